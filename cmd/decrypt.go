@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dylanmazurek/go-findmy/internal"
+	"github.com/dylanmazurek/go-findmy/internal/config"
+	"github.com/dylanmazurek/go-findmy/pkg/decryptor"
+	"github.com/dylanmazurek/go-findmy/pkg/nova/models/protos/bindings"
+	"google.golang.org/protobuf/proto"
+)
+
+func runDecrypt(ctx context.Context, cfg *config.Config, args []string) error {
+	flags, opts := newFlagSet("decrypt", "[flags] <file>")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return fmt.Errorf("expected a single payload file")
+	}
+
+	session, _, err := opts.loadSession(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	newDecryptor, err := decryptor.NewDecryptor(session.OwnerKey)
+	if err != nil {
+		return err
+	}
+
+	fcmPayloadEncoded, err := os.ReadFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	fcmPayload, err := decodeBase64(strings.TrimSpace(string(fcmPayloadEncoded)))
+	if err != nil {
+		return fmt.Errorf("unable to decode payload: %w", err)
+	}
+
+	var deviceUpdate bindings.DeviceUpdate
+	err = proto.Unmarshal(fcmPayload, &deviceUpdate)
+	if err != nil {
+		return err
+	}
+
+	reports, err := newDecryptor.DecryptDeviceUpdate(ctx, &deviceUpdate)
+	if err != nil {
+		return err
+	}
+
+	uniqueId, _ := internal.FormatUniqueId(deviceUpdate.GetDeviceMetadata())
+	for i := range reports {
+		reports[i].UniqueId = uniqueId
+	}
+
+	return printReports(opts, reports)
+}
+
+// decodeBase64 accepts payloads with or without padding, as copied from FCM
+func decodeBase64(value string) ([]byte, error) {
+	decoded, err := base64.StdEncoding.DecodeString(value)
+	if err == nil {
+		return decoded, nil
+	}
+
+	return base64.RawStdEncoding.DecodeString(value)
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dylanmazurek/go-findmy/internal/config"
+	"github.com/dylanmazurek/go-findmy/internal/findmy"
+	"github.com/dylanmazurek/go-findmy/pkg/notifier"
+	"github.com/dylanmazurek/go-findmy/pkg/nova"
+	shared "github.com/dylanmazurek/go-findmy/pkg/shared/models"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/rs/zerolog/log"
+)
+
+// reportSettleTime is how long locate keeps collecting reports after the first
+// one arrives, as a single FCM push usually carries several
+const reportSettleTime = 2 * time.Second
+
+func runDevices(ctx context.Context, cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: findmy devices list|locate <id>|ring <id>")
+	}
+
+	switch args[0] {
+	case "list":
+		return runDevicesList(ctx, cfg, args[1:])
+	case "locate":
+		return runDevicesLocate(ctx, cfg, args[1:])
+	case "ring":
+		return runDevicesRing(ctx, cfg, args[1:])
+	}
+
+	return fmt.Errorf("unknown devices command: %s", args[0])
+}
+
+func runDevicesList(ctx context.Context, cfg *config.Config, args []string) error {
+	flags, opts := newFlagSet("devices list", "[flags]")
+	flags.Parse(args)
+
+	session, _, err := opts.loadSession(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	novaClient, err := nova.NewClient(ctx,
+		nova.WithSession(notifier.NewSharedSession(session, opts.sessionSaver)),
+	)
+	if err != nil {
+		return err
+	}
+
+	devicesList, err := novaClient.GetDevices(ctx)
+	if err != nil {
+		return err
+	}
+
+	devices := findmy.NewDevices(ctx, devicesList)
+
+	return opts.print(devices, func(t table.Writer) {
+		t.AppendHeader(table.Row{"ID", "Name", "Model", "Manufacturer"})
+		for _, device := range devices {
+			t.AppendRow(table.Row{
+				device.UniqueId,
+				device.DeviceInfo.Name,
+				device.DeviceInfo.Model,
+				device.DeviceInfo.Manufacturer,
+			})
+		}
+	})
+}
+
+func runDevicesLocate(ctx context.Context, cfg *config.Config, args []string) error {
+	log := log.Ctx(ctx)
+
+	flags, opts := newFlagSet("devices locate", "[flags] <id>")
+	wait := flags.Duration("wait", time.Minute, "how long to wait for a location report, 0 to only request it")
+	flags.Parse(args)
+
+	uniqueId, err := deviceArg(flags.Args())
+	if err != nil {
+		return err
+	}
+
+	var mu sync.Mutex
+	var reports []shared.LocationReport
+	received := make(chan struct{}, 1)
+
+	listener := func(ctx context.Context, report shared.LocationReport) {
+		if report.UniqueId == nil || *report.UniqueId != uniqueId {
+			return
+		}
+
+		mu.Lock()
+		reports = append(reports, report)
+		mu.Unlock()
+
+		select {
+		case received <- struct{}{}:
+		default:
+		}
+	}
+
+	novaClient, notifierClient, err := newActionClients(ctx, cfg, opts, notifier.WithReportListener(listener))
+	if err != nil {
+		return err
+	}
+
+	if *wait > 0 {
+		err = notifierClient.StartListening(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = novaClient.LocateTracker(ctx, uniqueId)
+	if err != nil {
+		return err
+	}
+
+	log.Info().Str("unique_id", uniqueId).Msg("location requested")
+
+	if *wait == 0 {
+		return nil
+	}
+
+	select {
+	case <-received:
+		time.Sleep(reportSettleTime)
+	case <-time.After(*wait):
+		return fmt.Errorf("no location report received within %s", *wait)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	return printReports(opts, reports)
+}
+
+func runDevicesRing(ctx context.Context, cfg *config.Config, args []string) error {
+	log := log.Ctx(ctx)
+
+	flags, opts := newFlagSet("devices ring", "[flags] <id>")
+	componentFlag := flags.String("component", "", "component to ring: left, right or case")
+	stop := flags.Bool("stop", false, "stop a device that is ringing")
+	flags.Parse(args)
+
+	uniqueId, err := deviceArg(flags.Args())
+	if err != nil {
+		return err
+	}
+
+	component, err := nova.ParseDeviceComponent(*componentFlag)
+	if err != nil {
+		return err
+	}
+
+	novaClient, _, err := newActionClients(ctx, cfg, opts)
+	if err != nil {
+		return err
+	}
+
+	if *stop {
+		err = novaClient.StopSound(ctx, uniqueId, component)
+		if err != nil {
+			return err
+		}
+
+		log.Info().Str("unique_id", uniqueId).Msg("ringing stopped")
+
+		return nil
+	}
+
+	err = novaClient.PlaySound(ctx, uniqueId, component)
+	if err != nil {
+		return err
+	}
+
+	log.Info().Str("unique_id", uniqueId).Msg("ringing")
+
+	return nil
+}
+
+// newActionClients registers with FCM before creating the nova client, as
+// actions are answered over FCM and need its registration token
+func newActionClients(ctx context.Context, cfg *config.Config, opts *options, notifierOpts ...notifier.Option) (*nova.Client, *notifier.Client, error) {
+	session, semanticLocations, err := opts.loadSession(ctx, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	notifierOpts = append(notifierOpts,
+		notifier.WithSemanticLocations(semanticLocations),
+		notifier.WithSessionSaver(opts.sessionSaver),
+	)
+
+	notifierClient, err := notifier.NewClient(ctx, *session, notifierOpts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	novaClient, err := nova.NewClient(ctx,
+		nova.WithSession(notifierClient.SharedSession()),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return novaClient, notifierClient, nil
+}
+
+func deviceArg(args []string) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("expected a single device id")
+	}
+
+	return strings.ToLower(args[0]), nil
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dylanmazurek/go-findmy/internal/config"
+	"github.com/dylanmazurek/go-findmy/pkg/export"
+	"github.com/dylanmazurek/go-findmy/pkg/history"
+)
+
+// runExport writes the stored history of one or more devices as GPX, KML or GeoJSON
+func runExport(ctx context.Context, cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+
+	historyFile := flags.String("history", cfg.History.File, "history file to read reports from")
+	formatFlag := flags.String("format", string(export.FormatGeoJSON), "output format: gpx, kml or geojson")
+	devicesFlag := flags.String("devices", "", "comma separated unique ids to export, defaults to every device")
+	fromFlag := flags.String("from", "", "only export reports at or after this RFC 3339 time")
+	toFlag := flags.String("to", "", "only export reports at or before this RFC 3339 time")
+	outputFlag := flags.String("o", "", "output file, defaults to stdout")
+
+	flags.Parse(args)
+
+	format, err := export.ParseFormat(*formatFlag)
+	if err != nil {
+		return err
+	}
+
+	from, err := parseTime(*fromFlag)
+	if err != nil {
+		return err
+	}
+
+	to, err := parseTime(*toFlag)
+	if err != nil {
+		return err
+	}
+
+	// opening the store creates a missing file, which would silently export
+	// nothing for a mistyped path
+	_, err = os.Stat(*historyFile)
+	if err != nil {
+		return fmt.Errorf("unable to read history file: %w", err)
+	}
+
+	historyStore, err := history.NewFileStore(ctx, *historyFile)
+	if err != nil {
+		return err
+	}
+	defer historyStore.Close()
+
+	var uniqueIds []string
+	for _, uniqueId := range strings.Split(*devicesFlag, ",") {
+		uniqueId = strings.ToLower(strings.TrimSpace(uniqueId))
+		if uniqueId != "" {
+			uniqueIds = append(uniqueIds, uniqueId)
+		}
+	}
+
+	if len(uniqueIds) == 0 {
+		latest, err := historyStore.Latest(ctx)
+		if err != nil {
+			return err
+		}
+
+		for uniqueId := range latest {
+			uniqueIds = append(uniqueIds, uniqueId)
+		}
+
+		slices.Sort(uniqueIds)
+	}
+
+	var tracks []export.Track
+	for _, uniqueId := range uniqueIds {
+		reports, err := historyStore.Query(ctx, uniqueId, from, to)
+		if err != nil {
+			return err
+		}
+
+		tracks = append(tracks, export.NewTrack(uniqueId, "", reports))
+	}
+
+	var output io.Writer = os.Stdout
+	if *outputFlag != "" {
+		file, err := os.Create(*outputFlag)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		output = file
+	}
+
+	return export.Write(output, format, tracks)
+}
+
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	parsedTime, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q, expected RFC 3339: %w", value, err)
+	}
+
+	return parsedTime, nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+
+	"github.com/dylanmazurek/go-findmy/internal/config"
+	"github.com/dylanmazurek/go-findmy/pkg/notifier"
+	shared "github.com/dylanmazurek/go-findmy/pkg/shared/models"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/rs/zerolog/log"
+)
+
+func runListen(ctx context.Context, cfg *config.Config, args []string) error {
+	log := log.Ctx(ctx)
+
+	flags, opts := newFlagSet("listen", "[flags]")
+	flags.Parse(args)
+
+	session, semanticLocations, err := opts.loadSession(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+
+	// reports are printed one per line as they arrive rather than buffered
+	// into a table
+	listener := func(ctx context.Context, report shared.LocationReport) {
+		if opts.output == OUTPUT_JSON {
+			encoder.Encode(report)
+			return
+		}
+
+		t := table.NewWriter()
+		t.SetOutputMirror(os.Stdout)
+		t.SetStyle(table.StyleLight)
+		t.AppendRow(reportRow(report))
+		t.Render()
+	}
+
+	notifierClient, err := notifier.NewClient(ctx, *session,
+		notifier.WithSemanticLocations(semanticLocations),
+		notifier.WithReportListener(listener),
+		notifier.WithSessionSaver(opts.sessionSaver),
+	)
+	if err != nil {
+		return err
+	}
+
+	err = notifierClient.StartListening(ctx)
+	if err != nil {
+		return err
+	}
+
+	log.Info().Msg("listening for location reports")
+
+	<-ctx.Done()
+
+	log.Info().Msg("received terminate signal, stopping listener")
+
+	<-notifierClient.Done()
+
+	return nil
+}
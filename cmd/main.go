@@ -2,35 +2,88 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
 
-	"github.com/dylanmazurek/go-findmy/internal/findmy"
+	"github.com/dylanmazurek/go-findmy/internal/config"
+	"github.com/dylanmazurek/go-findmy/internal/config/constants"
 	"github.com/dylanmazurek/go-findmy/internal/logger"
 	"github.com/rs/zerolog/log"
 )
 
+type command struct {
+	name        string
+	description string
+	run         func(ctx context.Context, cfg *config.Config, args []string) error
+}
+
+var commands = []command{
+	{"devices", "list devices, request a location or ring a device", runDevices},
+	{"listen", "print decrypted location reports as they arrive", runListen},
+	{"decrypt", "decrypt a base64 encoded FCM payload file", runDecrypt},
+	{"session", "show or validate the session", runSession},
+	{"export", "export location history as GPX, KML or GeoJSON", runExport},
+	{"serve", "run the find-my service", runServe},
+}
+
 func main() {
 	ctx := context.Background()
-	ctx = logger.InitLogger(ctx)
 
-	findmyService, err := findmy.NewService(ctx)
-	if err != nil {
-		panic(err)
+	configFile, hasConfigFile := os.LookupEnv("CONFIG_FILE")
+	if !hasConfigFile {
+		configFile = constants.DEFAULT_CONFIG_FILE
 	}
 
-	err = findmyService.Start(ctx)
+	flag.StringVar(&configFile, "config", configFile, "config file, may also be set with CONFIG_FILE")
+	flag.Usage = usage
+	flag.Parse()
+
+	isConfigSet := hasConfigFile
+	flag.Visit(func(f *flag.Flag) {
+		isConfigSet = isConfigSet || f.Name == "config"
+	})
+
+	cfg, err := config.Load(configFile, isConfigSet)
 	if err != nil {
-		panic(err)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
 
-	sigs := make(chan os.Signal, 1)
-	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+	ctx = logger.InitLogger(ctx, cfg.LogLevel)
 
-	log.Info().Msg("go-findmy service running")
+	// commands stop when ctx is cancelled by SIGINT or SIGTERM
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	<-sigs
+	if flag.NArg() < 1 {
+		usage()
+		os.Exit(2)
+	}
 
-	log.Info().Msg("received terminate signal, stopping listener")
+	for _, cmd := range commands {
+		if cmd.name != flag.Arg(0) {
+			continue
+		}
+
+		err := cmd.run(ctx, cfg, flag.Args()[1:])
+		if err != nil {
+			stop()
+			log.Ctx(ctx).Fatal().Err(err).Str("command", cmd.name).Msg("command failed")
+		}
+
+		return
+	}
+
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: findmy [-config file] <command> [flags] [args]\n\ncommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.description)
+	}
 }
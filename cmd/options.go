@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/dylanmazurek/go-findmy/internal/config"
+	"github.com/dylanmazurek/go-findmy/internal/findmy"
+	"github.com/dylanmazurek/go-findmy/pkg/notifier"
+	"github.com/dylanmazurek/go-findmy/pkg/shared/constants"
+	shared "github.com/dylanmazurek/go-findmy/pkg/shared/models"
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+const (
+	SESSION_SOURCE_FILE    = "file"
+	SESSION_SOURCE_SECRETS = "secrets"
+
+	OUTPUT_TABLE = "table"
+	OUTPUT_JSON  = "json"
+)
+
+// options are the flags shared by every command that needs a session
+type options struct {
+	sessionSource string
+	sessionFile   string
+	output        string
+
+	// sessionSaver writes the session back to where it was loaded from, and
+	// is set by loadSession
+	sessionSaver notifier.SessionSaver
+}
+
+func newFlagSet(name string, usage string) (*flag.FlagSet, *options) {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: findmy %s %s\n", name, usage)
+		flags.PrintDefaults()
+	}
+
+	opts := &options{}
+	flags.StringVar(&opts.sessionSource, "session", SESSION_SOURCE_FILE, "session source: file, or secrets for the configured secrets provider")
+	flags.StringVar(&opts.sessionFile, "session-file", constants.DEFAULT_SESSION_FILE, "session file, used when -session=file")
+	flags.StringVar(&opts.output, "output", OUTPUT_TABLE, "output format: table or json")
+
+	return flags, opts
+}
+
+func (o *options) validate() error {
+	switch o.sessionSource {
+	case SESSION_SOURCE_FILE, SESSION_SOURCE_SECRETS:
+	default:
+		return fmt.Errorf("unknown session source: %s", o.sessionSource)
+	}
+
+	switch o.output {
+	case OUTPUT_TABLE, OUTPUT_JSON:
+	default:
+		return fmt.Errorf("unknown output format: %s", o.output)
+	}
+
+	return nil
+}
+
+// loadSession reads the session, along with the semantic locations when they
+// come from the secrets provider
+func (o *options) loadSession(ctx context.Context, cfg *config.Config) (*notifier.Session, []shared.SemanticLocation, error) {
+	err := o.validate()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if o.sessionSource == SESSION_SOURCE_SECRETS {
+		secretProvider, err := findmy.NewSecretProvider(ctx, cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		secret, err := secretProvider.GetSecret(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		session, err := findmy.SessionFromSecret(secret)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		semanticLocations, _ := findmy.SemanticLocationsFromSecret(secret)
+
+		o.sessionSaver = findmy.NewSessionSaver(secretProvider)
+
+		return session, semanticLocations, nil
+	}
+
+	sessionBytes, err := os.ReadFile(o.sessionFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to read session file: %w", err)
+	}
+
+	sessionStr := string(sessionBytes)
+
+	session, err := notifier.NewSession(ctx, &sessionStr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sessionFile := o.sessionFile
+	o.sessionSaver = func(ctx context.Context, session *notifier.Session) error {
+		return session.SaveSession(ctx, sessionFile)
+	}
+
+	return session, nil, nil
+}
+
+func (o *options) print(value any, rows func(t table.Writer)) error {
+	if o.output == OUTPUT_JSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(value)
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.SetStyle(table.StyleLight)
+
+	rows(t)
+
+	t.Render()
+
+	return nil
+}
+
+func reportRow(report shared.LocationReport) table.Row {
+	var uniqueId, semanticName string
+	if report.UniqueId != nil {
+		uniqueId = *report.UniqueId
+	}
+
+	if report.SemanticName != nil {
+		semanticName = *report.SemanticName
+	}
+
+	return table.Row{
+		uniqueId,
+		report.ReportTime.Local().Format("2006-01-02 15:04:05"),
+		report.Status.String(),
+		fmt.Sprintf("%.6f", report.Latitude),
+		fmt.Sprintf("%.6f", report.Longitude),
+		fmt.Sprintf("%.0f", report.Accuracy),
+		semanticName,
+	}
+}
+
+func reportHeader() table.Row {
+	return table.Row{"ID", "Time", "Status", "Latitude", "Longitude", "Accuracy", "Semantic"}
+}
+
+func printReports(opts *options, reports []shared.LocationReport) error {
+	return opts.print(reports, func(t table.Writer) {
+		t.AppendHeader(reportHeader())
+		for _, report := range reports {
+			t.AppendRow(reportRow(report))
+		}
+	})
+}
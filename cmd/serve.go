@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+	"flag"
+
+	"github.com/dylanmazurek/go-findmy/internal/config"
+	"github.com/dylanmazurek/go-findmy/internal/findmy"
+)
+
+// runServe runs the find-my service until SIGINT or SIGTERM. It is configured
+// by the config file, environment and secrets provider rather than flags
+func runServe(ctx context.Context, cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	flags.Parse(args)
+
+	findmyService, err := findmy.NewService(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	err = findmyService.Start(ctx)
+	if err != nil {
+		return err
+	}
+
+	return findmyService.Wait()
+}
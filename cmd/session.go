@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/dylanmazurek/go-findmy/internal/config"
+	"github.com/dylanmazurek/go-findmy/pkg/notifier"
+	"github.com/dylanmazurek/go-findmy/pkg/nova"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/rs/zerolog/log"
+)
+
+// sessionSummary describes a session without exposing any of its secrets
+type sessionSummary struct {
+	Email                 string `json:"email"`
+	AndroidId             bool   `json:"android_id"`
+	SecurityToken         bool   `json:"security_token"`
+	OwnerKey              bool   `json:"owner_key"`
+	AasToken              bool   `json:"aas_token"`
+	FcmRegistrationToken  bool   `json:"fcm_registration_token"`
+	FcmPrivateKey         bool   `json:"fcm_private_key"`
+	FcmAuthSecret         bool   `json:"fcm_auth_secret"`
+	FcmInstallationToken  bool   `json:"fcm_installation_token"`
+	FcmPersistentIdsCount int    `json:"fcm_persistent_ids"`
+}
+
+func newSessionSummary(session *notifier.Session) sessionSummary {
+	summary := sessionSummary{
+		Email:         session.GetEmail(),
+		AndroidId:     session.AndroidId != nil,
+		SecurityToken: session.SecurityToken != nil,
+		OwnerKey:      session.OwnerKey != nil && *session.OwnerKey != "",
+		AasToken:      session.AdmSession != nil && session.AdmSession.AasToken != "",
+	}
+
+	if session.FcmSession != nil {
+		summary.FcmRegistrationToken = session.FcmSession.RegistrationToken != nil
+		summary.FcmPrivateKey = session.FcmSession.PrivateKeyBase64 != nil
+		summary.FcmAuthSecret = session.FcmSession.AuthSecret != nil
+		summary.FcmInstallationToken = session.FcmSession.InstallationAuthToken != nil
+		summary.FcmPersistentIdsCount = len(session.FcmSession.PersistentIds)
+	}
+
+	return summary
+}
+
+func runSession(ctx context.Context, cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: findmy session show|validate")
+	}
+
+	switch args[0] {
+	case "show":
+		return runSessionShow(ctx, cfg, args[1:])
+	case "validate":
+		return runSessionValidate(ctx, cfg, args[1:])
+	}
+
+	return fmt.Errorf("unknown session command: %s", args[0])
+}
+
+func runSessionShow(ctx context.Context, cfg *config.Config, args []string) error {
+	flags, opts := newFlagSet("session show", "[flags]")
+	flags.Parse(args)
+
+	session, _, err := opts.loadSession(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	summary := newSessionSummary(session)
+
+	return opts.print(summary, func(t table.Writer) {
+		t.AppendHeader(table.Row{"Field", "Value"})
+		t.AppendRows([]table.Row{
+			{"Email", summary.Email},
+			{"Android id", summary.AndroidId},
+			{"Security token", summary.SecurityToken},
+			{"Owner key", summary.OwnerKey},
+			{"AAS token", summary.AasToken},
+			{"FCM registration token", summary.FcmRegistrationToken},
+			{"FCM private key", summary.FcmPrivateKey},
+			{"FCM auth secret", summary.FcmAuthSecret},
+			{"FCM installation token", summary.FcmInstallationToken},
+			{"FCM persistent ids", summary.FcmPersistentIdsCount},
+		})
+	})
+}
+
+// runSessionValidate checks the session has everything the service needs and
+// that its AAS token can still be exchanged for an ADM token
+func runSessionValidate(ctx context.Context, cfg *config.Config, args []string) error {
+	log := log.Ctx(ctx)
+
+	flags, opts := newFlagSet("session validate", "[flags]")
+	flags.Parse(args)
+
+	session, _, err := opts.loadSession(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	summary := newSessionSummary(session)
+
+	var errs []error
+	if session.Username == "" {
+		errs = append(errs, errors.New("username is missing"))
+	}
+
+	if !summary.OwnerKey {
+		errs = append(errs, errors.New("owner key is missing"))
+	}
+
+	if !summary.AasToken {
+		errs = append(errs, errors.New("aas token is missing"))
+	}
+
+	err = errors.Join(errs...)
+	if err != nil {
+		return err
+	}
+
+	_, err = nova.NewClient(ctx,
+		nova.WithSession(notifier.NewSharedSession(session, opts.sessionSaver)),
+	)
+	if err != nil {
+		return fmt.Errorf("unable to authenticate with session: %w", err)
+	}
+
+	log.Info().Str("email", summary.Email).Msg("session is valid")
+
+	return nil
+}
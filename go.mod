@@ -3,7 +3,6 @@ module github.com/dylanmazurek/go-findmy
 go 1.24
 
 require (
-	github.com/cenkalti/backoff/v4 v4.3.0 // indirect
 	github.com/go-jose/go-jose/v4 v4.0.1 // indirect
 	github.com/golang/protobuf v1.5.4 // indirect
 	github.com/gorilla/websocket v1.5.3 // indirect
@@ -38,6 +37,7 @@ require (
 
 require (
 	github.com/ProtonMail/go-crypto v1.1.5
+	github.com/cenkalti/backoff/v4 v4.3.0
 	github.com/deatil/go-cryptobin v1.0.5027
 	github.com/eclipse/paho.golang v0.22.0
 	github.com/go-co-op/gocron/v2 v2.15.0
@@ -46,11 +46,11 @@ require (
 	github.com/hashicorp/vault/api v1.16.0
 	github.com/hashicorp/vault/api/auth/approle v0.9.0
 	github.com/jedib0t/go-pretty/v6 v6.6.7
-	github.com/markkurossi/tabulate v0.0.0-20230223130100-d4965869b123
 	github.com/morhaviv/go-fcm-receiver v1.2.0
 	github.com/perimeterx/marshmallow v1.1.5
 	github.com/rs/zerolog v1.33.0
 	github.com/zeebo/xxh3 v1.0.2
 	golang.org/x/crypto v0.32.0
 	google.golang.org/protobuf v1.36.1
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -0,0 +1,9 @@
+package constants
+
+const (
+	SERVICE_NAME = "api"
+)
+
+const (
+	DEFAULT_ADDRESS = ":8080"
+)
@@ -0,0 +1,21 @@
+package api
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+//go:generate sh vendor-leaflet.sh
+
+//go:embed dashboard
+var dashboardFiles embed.FS
+
+func dashboardHandler() http.Handler {
+	dashboardFs, err := fs.Sub(dashboardFiles, "dashboard")
+	if err != nil {
+		panic(err)
+	}
+
+	return http.FileServerFS(dashboardFs)
+}
@@ -0,0 +1,252 @@
+"use strict";
+
+const TOKEN_KEY = "go-findmy-token";
+const TRAIL_HOURS = 24;
+
+const state = {
+  token: localStorage.getItem(TOKEN_KEY),
+  devices: new Map(),
+  events: null,
+};
+
+const map = L.map("map").setView([0, 0], 2);
+L.tileLayer("https://{s}.tile.openstreetmap.org/{z}/{x}/{y}.png", {
+  maxZoom: 19,
+  attribution: "&copy; OpenStreetMap contributors",
+}).addTo(map);
+
+const el = {
+  login: document.getElementById("login"),
+  token: document.getElementById("token"),
+  logout: document.getElementById("logout"),
+  devices: document.getElementById("devices"),
+  status: document.getElementById("status"),
+  trails: document.getElementById("trails"),
+};
+
+async function api(path, options = {}) {
+  const resp = await fetch(path, {
+    ...options,
+    headers: { Authorization: `Bearer ${state.token}` },
+  });
+
+  if (resp.status === 401) {
+    logout();
+    throw new Error("unauthorized");
+  }
+
+  if (!resp.ok) {
+    throw new Error(`${path}: ${resp.status}`);
+  }
+
+  return resp.json();
+}
+
+function formatAge(reportTime) {
+  const seconds = Math.max(0, (Date.now() - new Date(reportTime).getTime()) / 1000);
+
+  if (seconds < 60) return `${Math.round(seconds)}s ago`;
+  if (seconds < 3600) return `${Math.round(seconds / 60)}m ago`;
+  if (seconds < 86400) return `${Math.round(seconds / 3600)}h ago`;
+
+  return `${Math.round(seconds / 86400)}d ago`;
+}
+
+// semantic reports only have coordinates for known semantic locations
+function hasLocation(report) {
+  return report.report_type === "location" || report.latitude !== 0 || report.longitude !== 0;
+}
+
+function describe(report) {
+  const near = report.semantic_name ? ` near ${report.semantic_name}` : "";
+
+  return `${report.status}${near}, ±${Math.round(report.accuracy)}m, ${formatAge(report.report_time)}`;
+}
+
+function renderList() {
+  el.devices.replaceChildren();
+
+  for (const device of state.devices.values()) {
+    const item = document.createElement("li");
+
+    const name = document.createElement("div");
+    name.className = "name";
+    name.textContent = device.name;
+
+    const meta = document.createElement("div");
+    meta.className = "meta";
+
+    if (device.report) {
+      const badge = document.createElement("span");
+      badge.className = `badge ${device.report.status}`;
+      badge.textContent = device.report.report_type;
+
+      meta.append(badge, describe(device.report));
+    } else {
+      meta.textContent = "no location yet";
+    }
+
+    item.append(name, meta);
+    item.addEventListener("click", () => {
+      if (device.report && hasLocation(device.report)) {
+        map.setView([device.report.latitude, device.report.longitude], 16);
+        device.marker.openPopup();
+      }
+    });
+
+    el.devices.append(item);
+  }
+}
+
+function renderReport(device) {
+  const report = device.report;
+  if (!report || !hasLocation(report)) return;
+
+  const position = [report.latitude, report.longitude];
+
+  if (!device.marker) {
+    device.marker = L.marker(position).addTo(map);
+    device.circle = L.circle(position, { radius: report.accuracy, weight: 1 }).addTo(map);
+  }
+
+  device.marker.setLatLng(position);
+  device.marker.bindPopup(`<strong>${escapeHtml(device.name)}</strong><br>${escapeHtml(describe(report))}`);
+  device.circle.setLatLng(position);
+  device.circle.setRadius(report.accuracy);
+}
+
+function renderTrail(device) {
+  if (device.trail) {
+    device.trail.remove();
+    device.trail = null;
+  }
+
+  const points = device.history.filter(hasLocation).map((report) => [report.latitude, report.longitude]);
+  if (!el.trails.checked || points.length < 2) return;
+
+  device.trail = L.polyline(points, { weight: 2, opacity: 0.6 }).addTo(map);
+}
+
+function escapeHtml(value) {
+  const div = document.createElement("div");
+  div.textContent = value;
+
+  return div.innerHTML;
+}
+
+async function loadHistory(device) {
+  const from = new Date(Date.now() - TRAIL_HOURS * 3600 * 1000).toISOString();
+
+  try {
+    device.history = await api(`devices/${encodeURIComponent(device.id)}/history?from=${encodeURIComponent(from)}`);
+  } catch (err) {
+    device.history = [];
+  }
+
+  renderTrail(device);
+}
+
+async function loadDevices() {
+  const devices = await api("devices");
+
+  for (const device of devices) {
+    const entry = {
+      id: device.unique_id,
+      name: device.device.name,
+      report: null,
+      history: [],
+    };
+
+    state.devices.set(entry.id, entry);
+
+    try {
+      entry.report = await api(`devices/${encodeURIComponent(entry.id)}/location`);
+      renderReport(entry);
+    } catch (err) {
+      entry.report = null;
+    }
+
+    loadHistory(entry);
+  }
+
+  const bounds = [...state.devices.values()]
+    .filter((device) => device.report && hasLocation(device.report))
+    .map((device) => [device.report.latitude, device.report.longitude]);
+
+  if (bounds.length > 0) {
+    map.fitBounds(bounds, { padding: [40, 40], maxZoom: 16 });
+  }
+
+  renderList();
+}
+
+function onReport(report) {
+  const device = state.devices.get(report.unique_id);
+  if (!device) return;
+
+  if (!device.report || new Date(report.report_time) >= new Date(device.report.report_time)) {
+    device.report = report;
+    renderReport(device);
+  }
+
+  device.history.push(report);
+  device.history.sort((a, b) => new Date(a.report_time) - new Date(b.report_time));
+  renderTrail(device);
+  renderList();
+}
+
+function connectEvents() {
+  if (state.events) state.events.close();
+
+  state.events = new EventSource(`events?access_token=${encodeURIComponent(state.token)}`);
+  state.events.addEventListener("open", () => setStatus(true));
+  state.events.addEventListener("error", () => setStatus(false));
+  state.events.addEventListener("report", (event) => onReport(JSON.parse(event.data)));
+}
+
+function setStatus(connected) {
+  el.status.textContent = connected ? "live" : "disconnected";
+  el.status.classList.toggle("connected", connected);
+}
+
+function logout() {
+  localStorage.removeItem(TOKEN_KEY);
+  state.token = null;
+
+  if (state.events) state.events.close();
+
+  el.login.hidden = false;
+  el.logout.hidden = true;
+  setStatus(false);
+}
+
+async function start() {
+  el.login.hidden = true;
+  el.logout.hidden = false;
+
+  await loadDevices();
+  connectEvents();
+}
+
+el.login.addEventListener("submit", (event) => {
+  event.preventDefault();
+
+  state.token = el.token.value;
+  localStorage.setItem(TOKEN_KEY, state.token);
+
+  start().catch((err) => console.error(err));
+});
+
+el.logout.addEventListener("click", logout);
+
+el.trails.addEventListener("change", () => {
+  for (const device of state.devices.values()) renderTrail(device);
+});
+
+setInterval(renderList, 30 * 1000);
+
+if (state.token) {
+  start().catch((err) => console.error(err));
+} else {
+  el.login.hidden = false;
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	shared "github.com/dylanmazurek/go-findmy/pkg/shared/models"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	eventBufferSize   = 16
+	keepAliveInterval = 30 * time.Second
+)
+
+// broker fans decrypted reports out to Server-Sent Event listeners. Slow
+// listeners drop events rather than holding up the notifier.
+type broker struct {
+	mu        sync.Mutex
+	listeners map[chan []byte]struct{}
+
+	// closed ends every stream so the server can shut down
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func newBroker() *broker {
+	newBroker := &broker{
+		listeners: make(map[chan []byte]struct{}),
+		closed:    make(chan struct{}),
+	}
+
+	return newBroker
+}
+
+func (b *broker) close() {
+	b.closeOnce.Do(func() {
+		close(b.closed)
+	})
+}
+
+func (b *broker) publish(ctx context.Context, report shared.LocationReport) {
+	log := log.Ctx(ctx)
+
+	reportJson, err := json.Marshal(report)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to marshal report event")
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for listener := range b.listeners {
+		select {
+		case listener <- reportJson:
+		default:
+			log.Warn().Msg("event listener is too slow, dropping report")
+		}
+	}
+}
+
+func (b *broker) subscribe() chan []byte {
+	listener := make(chan []byte, eventBufferSize)
+
+	b.mu.Lock()
+	b.listeners[listener] = struct{}{}
+	b.mu.Unlock()
+
+	return listener
+}
+
+func (b *broker) unsubscribe(listener chan []byte) {
+	b.mu.Lock()
+	delete(b.listeners, listener)
+	b.mu.Unlock()
+}
+
+func (b *broker) count() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(b.listeners)
+}
+
+func (b *broker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("streaming is not supported"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	listener := b.subscribe()
+	defer b.unsubscribe(listener)
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-b.closed:
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case reportJson := <-listener:
+			fmt.Fprintf(w, "event: report\ndata: %s\n\n", reportJson)
+		}
+
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,158 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/dylanmazurek/go-findmy/pkg/nova"
+	shared "github.com/dylanmazurek/go-findmy/pkg/shared/models"
+	"github.com/rs/zerolog/log"
+)
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+type statusResponse struct {
+	Status string `json:"status"`
+}
+
+func writeJson(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJson(w, status, errorResponse{Error: err.Error()})
+}
+
+func (s *Server) handleGetDevices(w http.ResponseWriter, r *http.Request) {
+	devices, err := s.service.GetDevices(r.Context())
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msg("failed to get devices")
+		writeError(w, http.StatusBadGateway, err)
+
+		return
+	}
+
+	writeJson(w, http.StatusOK, devices)
+}
+
+func (s *Server) handleGetLocation(w http.ResponseWriter, r *http.Request) {
+	uniqueId := r.PathValue("id")
+
+	report, err := s.service.LatestReport(r.Context(), uniqueId)
+	if errors.Is(err, ErrNotFound) || (err == nil && report == nil) {
+		writeError(w, http.StatusNotFound, ErrNotFound)
+		return
+	}
+
+	if errors.Is(err, ErrHistoryUnavailable) {
+		writeError(w, http.StatusNotImplemented, err)
+		return
+	}
+
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJson(w, http.StatusOK, report)
+}
+
+func (s *Server) handleGetHistory(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var from, to time.Time
+	var err error
+
+	if query.Has("from") {
+		from, err = time.Parse(time.RFC3339, query.Get("from"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid from: %w", err))
+			return
+		}
+	}
+
+	if query.Has("to") {
+		to, err = time.Parse(time.RFC3339, query.Get("to"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid to: %w", err))
+			return
+		}
+	}
+
+	reports, err := s.service.History(r.Context(), r.PathValue("id"), from, to)
+	if errors.Is(err, ErrHistoryUnavailable) {
+		writeError(w, http.StatusNotImplemented, err)
+		return
+	}
+
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if reports == nil {
+		reports = []shared.LocationReport{}
+	}
+
+	writeJson(w, http.StatusOK, reports)
+}
+
+func (s *Server) handleLocate(w http.ResponseWriter, r *http.Request) {
+	err := s.service.LocateDevice(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+
+	writeJson(w, http.StatusAccepted, statusResponse{Status: "locating"})
+}
+
+func (s *Server) handleRing(w http.ResponseWriter, r *http.Request) {
+	component, err := nova.ParseDeviceComponent(r.URL.Query().Get("component"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	err = s.service.PlaySound(r.Context(), r.PathValue("id"), component)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+
+	writeJson(w, http.StatusAccepted, statusResponse{Status: "ringing"})
+}
+
+func (s *Server) handleStopRing(w http.ResponseWriter, r *http.Request) {
+	component, err := nova.ParseDeviceComponent(r.URL.Query().Get("component"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	err = s.service.StopSound(r.Context(), r.PathValue("id"), component)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+
+	writeJson(w, http.StatusAccepted, statusResponse{Status: "stopped"})
+}
+
+func (s *Server) handleRefresh(w http.ResponseWriter, r *http.Request) {
+	err := s.service.RefreshDevices(r.Context())
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+
+	writeJson(w, http.StatusAccepted, statusResponse{Status: "refreshing"})
+}
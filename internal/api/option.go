@@ -0,0 +1,30 @@
+package api
+
+import "github.com/dylanmazurek/go-findmy/internal/api/constants"
+
+type Options struct {
+	address string
+	token   string
+}
+
+func DefaultOptions() Options {
+	defaultOptions := Options{
+		address: constants.DEFAULT_ADDRESS,
+	}
+
+	return defaultOptions
+}
+
+type Option func(*Options)
+
+func WithAddress(address string) Option {
+	return func(o *Options) {
+		o.address = address
+	}
+}
+
+func WithToken(token string) Option {
+	return func(o *Options) {
+		o.token = token
+	}
+}
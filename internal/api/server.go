@@ -0,0 +1,148 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dylanmazurek/go-findmy/internal/api/constants"
+	pubModels "github.com/dylanmazurek/go-findmy/internal/publisher/models"
+	"github.com/dylanmazurek/go-findmy/pkg/nova/models/protos/bindings"
+	shared "github.com/dylanmazurek/go-findmy/pkg/shared/models"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	ErrMissingToken       = errors.New("api token is not set")
+	ErrNotFound           = errors.New("not found")
+	ErrHistoryUnavailable = errors.New("history is not available")
+)
+
+type Service interface {
+	GetDevices(ctx context.Context) ([]pubModels.Device, error)
+	LatestReport(ctx context.Context, uniqueId string) (*shared.LocationReport, error)
+	History(ctx context.Context, uniqueId string, from time.Time, to time.Time) ([]shared.LocationReport, error)
+	LocateDevice(ctx context.Context, uniqueId string) error
+	PlaySound(ctx context.Context, uniqueId string, component bindings.DeviceComponent) error
+	StopSound(ctx context.Context, uniqueId string, component bindings.DeviceComponent) error
+	RefreshDevices(ctx context.Context) error
+}
+
+type Server struct {
+	service Service
+	token   string
+	events  *broker
+
+	mux        *http.ServeMux
+	httpServer *http.Server
+}
+
+func NewServer(ctx context.Context, service Service, opts ...Option) (*Server, error) {
+	serverOptions := DefaultOptions()
+	for _, opt := range opts {
+		opt(&serverOptions)
+	}
+
+	if serverOptions.token == "" {
+		return nil, ErrMissingToken
+	}
+
+	newServer := &Server{
+		service: service,
+		token:   serverOptions.token,
+		events:  newBroker(),
+
+		mux: http.NewServeMux(),
+	}
+
+	newServer.routes()
+
+	newServer.httpServer = &http.Server{
+		Addr:              serverOptions.address,
+		Handler:           newServer.mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext: func(net.Listener) context.Context {
+			return ctx
+		},
+	}
+
+	// event streams never end on their own, so they are closed rather than
+	// holding up Shutdown
+	newServer.httpServer.RegisterOnShutdown(newServer.events.close)
+
+	return newServer, nil
+}
+
+func (s *Server) routes() {
+	dashboard := dashboardHandler()
+	s.mux.Handle("GET /{$}", dashboard)
+	s.mux.Handle("GET /assets/", dashboard)
+
+	s.mux.Handle("GET /devices", s.authenticate(s.handleGetDevices))
+	s.mux.Handle("GET /devices/{id}/location", s.authenticate(s.handleGetLocation))
+	s.mux.Handle("GET /devices/{id}/history", s.authenticate(s.handleGetHistory))
+	s.mux.Handle("POST /devices/{id}/locate", s.authenticate(s.handleLocate))
+	s.mux.Handle("POST /devices/{id}/ring", s.authenticate(s.handleRing))
+	s.mux.Handle("POST /devices/{id}/stop_ring", s.authenticate(s.handleStopRing))
+	s.mux.Handle("POST /refresh", s.authenticate(s.handleRefresh))
+	s.mux.Handle("GET /events", s.authenticate(s.events.ServeHTTP))
+}
+
+// PublishReport streams a decrypted report to every connected event listener
+func (s *Server) PublishReport(ctx context.Context, report shared.LocationReport) {
+	s.events.publish(ctx, report)
+}
+
+func (s *Server) Handler() http.Handler {
+	return s.httpServer.Handler
+}
+
+// Start listens in the background until Shutdown is called
+func (s *Server) Start(ctx context.Context) error {
+	log := log.Ctx(ctx).With().Str("service", constants.SERVICE_NAME).Logger()
+
+	listener, err := net.Listen("tcp", s.httpServer.Addr)
+	if err != nil {
+		return err
+	}
+
+	log.Info().Str("address", listener.Addr().String()).Msg("api listening")
+
+	go func() {
+		err := s.httpServer.Serve(listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error().Err(err).Msg("api server stopped")
+		}
+	}()
+
+	return nil
+}
+
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.httpServer.Shutdown(ctx)
+}
+
+// authenticate checks the bearer token, falling back to the access_token query
+// parameter for clients such as EventSource that cannot set headers
+func (s *Server) authenticate(next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeader := r.Header.Get("Authorization")
+		token, hasBearer := strings.CutPrefix(authHeader, "Bearer ")
+		if !hasBearer && r.URL.Query().Has("access_token") {
+			token, hasBearer = r.URL.Query().Get("access_token"), true
+		}
+
+		if !hasBearer || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,271 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	pubModels "github.com/dylanmazurek/go-findmy/internal/publisher/models"
+	"github.com/dylanmazurek/go-findmy/pkg/nova/models/protos/bindings"
+	shared "github.com/dylanmazurek/go-findmy/pkg/shared/models"
+)
+
+type fakeService struct {
+	reports   map[string]shared.LocationReport
+	rung      []string
+	component bindings.DeviceComponent
+}
+
+func (f *fakeService) GetDevices(ctx context.Context) ([]pubModels.Device, error) {
+	return []pubModels.Device{pubModels.NewDevice("Keys", "tracker-a", "Tag", "Acme")}, nil
+}
+
+func (f *fakeService) LatestReport(ctx context.Context, uniqueId string) (*shared.LocationReport, error) {
+	report, ok := f.reports[uniqueId]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return &report, nil
+}
+
+func (f *fakeService) History(ctx context.Context, uniqueId string, from time.Time, to time.Time) ([]shared.LocationReport, error) {
+	report, ok := f.reports[uniqueId]
+	if !ok {
+		return nil, nil
+	}
+
+	return []shared.LocationReport{report}, nil
+}
+
+func (f *fakeService) LocateDevice(ctx context.Context, uniqueId string) error {
+	return nil
+}
+
+func (f *fakeService) PlaySound(ctx context.Context, uniqueId string, component bindings.DeviceComponent) error {
+	f.rung = append(f.rung, uniqueId)
+	f.component = component
+
+	return nil
+}
+
+func (f *fakeService) StopSound(ctx context.Context, uniqueId string, component bindings.DeviceComponent) error {
+	return nil
+}
+
+func (f *fakeService) RefreshDevices(ctx context.Context) error {
+	return nil
+}
+
+func newTestServer(t *testing.T, service Service) http.Handler {
+	t.Helper()
+
+	server, err := NewServer(context.Background(), service, WithToken("secret"))
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+
+	return server.Handler()
+}
+
+func doRequest(handler http.Handler, method string, path string, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestAuthentication(t *testing.T) {
+	handler := newTestServer(t, &fakeService{})
+
+	for _, token := range []string{"", "wrong"} {
+		rec := doRequest(handler, http.MethodGet, "/devices", token)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("token %q: expected 401, got %d", token, rec.Code)
+		}
+	}
+
+	rec := doRequest(handler, http.MethodGet, "/devices", "secret")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+}
+
+func TestDashboard(t *testing.T) {
+	handler := newTestServer(t, &fakeService{})
+
+	for _, path := range []string{"/", "/assets/app.js"} {
+		rec := doRequest(handler, http.MethodGet, path, "")
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s: expected dashboard to be served without a token, got %d", path, rec.Code)
+		}
+	}
+}
+
+func TestNewServerRequiresToken(t *testing.T) {
+	_, err := NewServer(context.Background(), &fakeService{})
+	if err != ErrMissingToken {
+		t.Errorf("expected ErrMissingToken, got %v", err)
+	}
+}
+
+func TestGetLocation(t *testing.T) {
+	uniqueId := "tracker-a"
+	service := &fakeService{
+		reports: map[string]shared.LocationReport{
+			uniqueId: {
+				UniqueId:   &uniqueId,
+				ReportType: shared.ReportTypeLocation,
+				ReportTime: time.Unix(1700000000, 0).UTC(),
+				Status:     shared.ReportStatusCrowdsourced,
+				Latitude:   -37.8136,
+				Longitude:  144.9631,
+				Accuracy:   12,
+			},
+		},
+	}
+
+	handler := newTestServer(t, service)
+
+	rec := doRequest(handler, http.MethodGet, "/devices/tracker-a/location", "secret")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	var report map[string]any
+	err := json.Unmarshal(rec.Body.Bytes(), &report)
+	if err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if report["status"] != "crowdsourced" || report["report_type"] != "location" {
+		t.Errorf("unexpected report: %v", report)
+	}
+
+	rec = doRequest(handler, http.MethodGet, "/devices/unknown/location", "secret")
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown device, got %d", rec.Code)
+	}
+}
+
+func TestRing(t *testing.T) {
+	service := &fakeService{}
+	handler := newTestServer(t, service)
+
+	rec := doRequest(handler, http.MethodPost, "/devices/tracker-a/ring?component=left", "secret")
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", rec.Code)
+	}
+
+	if len(service.rung) != 1 || service.component != bindings.DeviceComponent_DEVICE_COMPONENT_LEFT {
+		t.Errorf("expected left component of tracker-a to ring, got %v %v", service.rung, service.component)
+	}
+
+	rec = doRequest(handler, http.MethodPost, "/devices/tracker-a/ring?component=nose", "secret")
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for unknown component, got %d", rec.Code)
+	}
+
+	rec = doRequest(handler, http.MethodGet, "/devices/tracker-a/ring", "secret")
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405 for GET ring, got %d", rec.Code)
+	}
+}
+
+func TestGetHistory(t *testing.T) {
+	handler := newTestServer(t, &fakeService{})
+
+	rec := doRequest(handler, http.MethodGet, "/devices/unknown/history?from=2024-01-01T00:00:00Z", "secret")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	if body := rec.Body.String(); body != "[]\n" {
+		t.Errorf("expected empty list, got %q", body)
+	}
+
+	rec = doRequest(handler, http.MethodGet, "/devices/unknown/history?from=yesterday", "secret")
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for invalid from, got %d", rec.Code)
+	}
+}
+
+func TestEventsAcceptsQueryToken(t *testing.T) {
+	uniqueId := "tracker-a"
+
+	server, err := NewServer(context.Background(), &fakeService{}, WithToken("secret"))
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+
+	httpServer := httptest.NewServer(server.Handler())
+	defer httpServer.Close()
+
+	resp, err := http.Get(httpServer.URL + "/events?access_token=secret")
+	if err != nil {
+		t.Fatalf("GET /events: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	for server.events.count() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	server.PublishReport(context.Background(), shared.LocationReport{UniqueId: &uniqueId})
+
+	reader := bufio.NewReader(resp.Body)
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		t.Fatalf("failed to read event: %v", err)
+	}
+
+	if line != "event: report\n" {
+		t.Errorf("expected report event, got %q", line)
+	}
+}
+
+func TestShutdownClosesEventStreams(t *testing.T) {
+	server, err := NewServer(context.Background(), &fakeService{}, WithToken("secret"))
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go server.httpServer.Serve(listener)
+
+	resp, err := http.Get("http://" + listener.Addr().String() + "/events?access_token=secret")
+	if err != nil {
+		t.Fatalf("GET /events: %v", err)
+	}
+	defer resp.Body.Close()
+
+	for server.events.count() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	err = server.Shutdown(ctx)
+	if err != nil {
+		t.Fatalf("expected shutdown to close event streams, got %v", err)
+	}
+}
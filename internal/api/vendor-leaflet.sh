@@ -0,0 +1,35 @@
+#!/bin/sh
+# Fetches the Leaflet release served by the dashboard into
+# dashboard/assets/leaflet, checking it against the published SRI hashes
+set -eu
+
+LEAFLET_VERSION="1.9.4"
+LEAFLET_JS_SHA256="20nQCchB9co0qIjJZRGuk2/Z9VM+kNiyxNV1lvTlZBo="
+LEAFLET_CSS_SHA256="p4NxAoJBhIIN+hmNHrzRCf9tD/miZyoHS5obTRR9BMY="
+
+cd "$(dirname "$0")"
+
+dest="dashboard/assets/leaflet"
+tmp="$(mktemp -d)"
+trap 'rm -rf "$tmp"' EXIT
+
+curl -fsSL "https://registry.npmjs.org/leaflet/-/leaflet-${LEAFLET_VERSION}.tgz" | tar -xz -C "$tmp"
+
+sri() {
+	openssl dgst -sha256 -binary "$1" | openssl base64 -A
+}
+
+if [ "$(sri "$tmp/package/dist/leaflet.js")" != "$LEAFLET_JS_SHA256" ]; then
+	echo "leaflet.js does not match the expected hash" >&2
+	exit 1
+fi
+
+if [ "$(sri "$tmp/package/dist/leaflet.css")" != "$LEAFLET_CSS_SHA256" ]; then
+	echo "leaflet.css does not match the expected hash" >&2
+	exit 1
+fi
+
+rm -rf "$dest"
+mkdir -p "$dest/images"
+cp "$tmp/package/dist/leaflet.js" "$tmp/package/dist/leaflet.css" "$tmp/package/LICENSE" "$dest/"
+cp "$tmp/package/dist/images/"*.png "$dest/images/"
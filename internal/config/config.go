@@ -0,0 +1,255 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/dylanmazurek/go-findmy/internal/config/constants"
+	"github.com/dylanmazurek/go-findmy/pkg/geofence"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the go-findmy configuration. It is loaded from a YAML file, then
+// overridden by environment variables, see env.go for their names.
+type Config struct {
+	LogLevel     string `yaml:"log_level"`
+	Timezone     string `yaml:"timezone"`
+	CronSchedule string `yaml:"cron_schedule"`
+
+	Secrets   SecretsConfig   `yaml:"secrets"`
+	Vault     VaultConfig     `yaml:"vault"`
+	History   HistoryConfig   `yaml:"history"`
+	Dedup     DedupConfig     `yaml:"dedup"`
+	Selection SelectionConfig `yaml:"selection"`
+	Api       ApiConfig       `yaml:"api"`
+
+	Publishers []string        `yaml:"publishers"`
+	Mqtt       MqttConfig      `yaml:"mqtt"`
+	File       FileConfig      `yaml:"file"`
+	Webhook    WebhookConfig   `yaml:"webhook"`
+	OwnTracks  OwnTracksConfig `yaml:"owntracks"`
+	Traccar    TraccarConfig   `yaml:"traccar"`
+
+	Geofences           []geofence.Geofence `yaml:"geofences"`
+	GeofenceMaxAccuracy float64             `yaml:"geofence_max_accuracy"`
+}
+
+// SecretsConfig selects where the session, semantic locations and
+// credentials are read from
+type SecretsConfig struct {
+	Provider  string `yaml:"provider"`
+	File      string `yaml:"file"`
+	Directory string `yaml:"directory"`
+	EnvPrefix string `yaml:"env_prefix"`
+}
+
+type VaultConfig struct {
+	Address   string `yaml:"address"`
+	AppRoleId string `yaml:"approle_id"`
+	SecretId  string `yaml:"secret_id"`
+	Mount     string `yaml:"mount"`
+	Path      string `yaml:"path"`
+}
+
+// HistoryConfig drops reports older than MaxAge, keeping every report when it
+// is zero
+type HistoryConfig struct {
+	File   string        `yaml:"file"`
+	MaxAge time.Duration `yaml:"max_age"`
+}
+
+type DedupConfig struct {
+	File     string        `yaml:"file"`
+	Capacity int           `yaml:"capacity"`
+	TTL      time.Duration `yaml:"ttl"`
+}
+
+// SelectionConfig picks which report of a device update is published.
+// MaxSpeed is in km/h, reports implying a faster jump are rejected unless it
+// is zero
+type SelectionConfig struct {
+	Policy   string        `yaml:"policy"`
+	Window   time.Duration `yaml:"window"`
+	MaxSpeed float64       `yaml:"max_speed"`
+}
+
+// ApiConfig enables the HTTP API when a token is set
+type ApiConfig struct {
+	Address string `yaml:"address"`
+	Token   string `yaml:"token"`
+}
+
+// MqttConfig configures the Home Assistant publisher. Devices are marked
+// unavailable once they have not reported for StaleAfter, or never when it is
+// zero. ConfigurationUrl is linked from the go-findmy device in Home Assistant
+type MqttConfig struct {
+	Url              string        `yaml:"url"`
+	Username         string        `yaml:"username"`
+	Password         string        `yaml:"password"`
+	StaleAfter       time.Duration `yaml:"stale_after"`
+	ConfigurationUrl string        `yaml:"configuration_url"`
+}
+
+type FileConfig struct {
+	Path string `yaml:"path"`
+}
+
+type WebhookConfig struct {
+	Url      string `yaml:"url"`
+	Secret   string `yaml:"secret"`
+	SpoolDir string `yaml:"spool_dir"`
+}
+
+type OwnTracksConfig struct {
+	Mode     string `yaml:"mode"`
+	Url      string `yaml:"url"`
+	User     string `yaml:"user"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+type TraccarConfig struct {
+	Url       string            `yaml:"url"`
+	DeviceIds map[string]string `yaml:"device_ids"`
+}
+
+func Default() *Config {
+	defaultConfig := &Config{
+		LogLevel:     constants.DEFAULT_LOG_LEVEL,
+		Timezone:     constants.DEFAULT_TIMEZONE,
+		CronSchedule: constants.DEFAULT_CRON_SCHEDULE,
+		Secrets: SecretsConfig{
+			Provider:  constants.DEFAULT_SECRETS_PROVIDER,
+			Directory: constants.DEFAULT_SECRETS_DIRECTORY,
+			EnvPrefix: constants.DEFAULT_SECRETS_ENV_PREFIX,
+		},
+		Vault: VaultConfig{
+			Mount: constants.DEFAULT_VAULT_MOUNT,
+			Path:  constants.DEFAULT_VAULT_PATH,
+		},
+		History: HistoryConfig{
+			File:   constants.DEFAULT_HISTORY_FILE,
+			MaxAge: constants.DEFAULT_HISTORY_MAX_AGE,
+		},
+		Dedup: DedupConfig{
+			Capacity: constants.DEFAULT_DEDUP_CAPACITY,
+			TTL:      constants.DEFAULT_DEDUP_TTL,
+		},
+		Selection: SelectionConfig{
+			Policy: constants.DEFAULT_SELECTION_POLICY,
+			Window: constants.DEFAULT_SELECTION_WINDOW,
+		},
+		Api: ApiConfig{
+			Address: constants.DEFAULT_API_ADDRESS,
+		},
+		Publishers: constants.DEFAULT_PUBLISHERS,
+		Mqtt: MqttConfig{
+			StaleAfter: constants.DEFAULT_MQTT_STALE_AFTER,
+		},
+		File: FileConfig{
+			Path: constants.DEFAULT_PUBLISH_FILE,
+		},
+		Webhook: WebhookConfig{
+			SpoolDir: constants.DEFAULT_WEBHOOK_SPOOL_DIR,
+		},
+		OwnTracks: OwnTracksConfig{
+			Mode: constants.DEFAULT_OWNTRACKS_MODE,
+			User: constants.DEFAULT_OWNTRACKS_USER,
+		},
+		Traccar: TraccarConfig{
+			DeviceIds: map[string]string{},
+		},
+		GeofenceMaxAccuracy: constants.DEFAULT_GEOFENCE_MAX_ACCURACY,
+	}
+
+	return defaultConfig
+}
+
+// Load reads the config file at path on top of the defaults and applies the
+// environment overrides. A missing file is only an error when required is set,
+// so the service still runs from the environment alone. The result isn't
+// validated as most CLI commands only need part of it, call Validate before
+// starting the service.
+func Load(path string, required bool) (*Config, error) {
+	cfg := Default()
+
+	configBytes, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist) && !required:
+	case err != nil:
+		return nil, fmt.Errorf("unable to read config file: %w", err)
+	default:
+		err = cfg.decode(bytes.NewReader(configBytes))
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse config file %s: %w", path, err)
+		}
+	}
+
+	err = cfg.applyEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+func (c *Config) decode(r io.Reader) error {
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+
+	err := decoder.Decode(c)
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+
+	return err
+}
+
+// HasPublisher reports whether the named publisher is enabled
+func (c *Config) HasPublisher(name string) bool {
+	for _, publisher := range c.Publishers {
+		if publisher == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ApplySecret fills settings that weren't configured from the matching keys
+// of the secret, so credentials can stay in the secret store
+func (c *Config) ApplySecret(secret map[string]any) {
+	secretString(secret, "MQTT_USERNAME", &c.Mqtt.Username)
+	secretString(secret, "MQTT_PASSWORD", &c.Mqtt.Password)
+	secretString(secret, "API_TOKEN", &c.Api.Token)
+	secretString(secret, "WEBHOOK_URL", &c.Webhook.Url)
+	secretString(secret, "WEBHOOK_SECRET", &c.Webhook.Secret)
+	secretString(secret, "OWNTRACKS_USERNAME", &c.OwnTracks.Username)
+	secretString(secret, "OWNTRACKS_PASSWORD", &c.OwnTracks.Password)
+	secretString(secret, "TRACCAR_URL", &c.Traccar.Url)
+
+	deviceIds, hasDeviceIds := secret["TRACCAR_DEVICE_IDS"].(map[string]any)
+	if hasDeviceIds {
+		for uniqueId, deviceId := range deviceIds {
+			_, isSet := c.Traccar.DeviceIds[uniqueId]
+			if !isSet {
+				c.Traccar.DeviceIds[uniqueId] = fmt.Sprint(deviceId)
+			}
+		}
+	}
+}
+
+func secretString(secret map[string]any, key string, target *string) {
+	if *target != "" {
+		return
+	}
+
+	value, ok := secret[key].(string)
+	if ok {
+		*target = value
+	}
+}
@@ -0,0 +1,195 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dylanmazurek/go-findmy/internal/config/constants"
+)
+
+func writeConfig(t *testing.T, contents string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	err := os.WriteFile(path, []byte(contents), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestLoadDefaults(t *testing.T) {
+	cfg, err := Load(filepath.Join(t.TempDir(), "missing.yaml"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Timezone != constants.DEFAULT_TIMEZONE || cfg.CronSchedule != constants.DEFAULT_CRON_SCHEDULE {
+		t.Fatalf("expected defaults, got %+v", cfg)
+	}
+
+	if cfg.Vault.Mount != "kv" || cfg.Vault.Path != "go-findmy" {
+		t.Fatalf("expected the default vault path, got %+v", cfg.Vault)
+	}
+
+	if cfg.History.MaxAge != constants.DEFAULT_HISTORY_MAX_AGE {
+		t.Fatalf("expected a finite default history max age, got %v", cfg.History.MaxAge)
+	}
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.yaml"), true)
+	if err == nil {
+		t.Fatal("expected an error for a missing required config file")
+	}
+}
+
+func TestLoadFileWithEnvOverrides(t *testing.T) {
+	path := writeConfig(t, `
+timezone: UTC
+publishers: [stdout, traccar]
+secrets:
+  provider: file
+  file: secrets.yaml
+vault:
+  path: findmy/prod
+dedup:
+  ttl: 2h
+traccar:
+  url: http://traccar:5055
+  device_ids:
+    abc: keys
+`)
+
+	t.Setenv("CRON_SCHEDULE", "*/5 * * * *")
+	t.Setenv("TRACCAR_DEVICE_IDS", "def=bag")
+
+	cfg, err := Load(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = cfg.Validate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Timezone != "UTC" || cfg.CronSchedule != "*/5 * * * *" {
+		t.Fatalf("unexpected schedule settings %s %s", cfg.Timezone, cfg.CronSchedule)
+	}
+
+	if cfg.Vault.Mount != "kv" || cfg.Vault.Path != "findmy/prod" {
+		t.Fatalf("unexpected vault settings %+v", cfg.Vault)
+	}
+
+	if cfg.Dedup.TTL != 2*time.Hour || cfg.Dedup.Capacity != constants.DEFAULT_DEDUP_CAPACITY {
+		t.Fatalf("unexpected dedup settings %+v", cfg.Dedup)
+	}
+
+	if cfg.Traccar.DeviceIds["abc"] != "keys" || cfg.Traccar.DeviceIds["def"] != "bag" {
+		t.Fatalf("unexpected traccar device ids %v", cfg.Traccar.DeviceIds)
+	}
+}
+
+func TestLoadMapsPublishMqtt(t *testing.T) {
+	tests := []struct {
+		name        string
+		publishers  string
+		publishMqtt string
+		expected    []string
+		expectErr   bool
+	}{
+		{name: "true keeps mqtt", publishMqtt: "true", expected: []string{"mqtt"}},
+		{name: "true adds mqtt", publishers: "stdout", publishMqtt: "true", expected: []string{"stdout", "mqtt"}},
+		{name: "false removes mqtt", publishers: "mqtt,webhook", publishMqtt: "false", expected: []string{"webhook"}},
+		{name: "false without other publishers", publishMqtt: "false", expectErr: true},
+		{name: "invalid", publishMqtt: "yes please", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.publishers != "" {
+				t.Setenv("PUBLISHERS", tt.publishers)
+			}
+
+			t.Setenv("PUBLISH_MQTT", tt.publishMqtt)
+
+			cfg, err := Load(filepath.Join(t.TempDir(), "missing.yaml"), false)
+			if tt.expectErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !slices.Equal(cfg.Publishers, tt.expected) {
+				t.Fatalf("expected publishers %v, got %v", tt.expected, cfg.Publishers)
+			}
+		})
+	}
+}
+
+func TestLoadRejectsUnknownKeys(t *testing.T) {
+	path := writeConfig(t, "cron: '* * * * *'\n")
+
+	_, err := Load(path, true)
+	if err == nil || !strings.Contains(err.Error(), "cron") {
+		t.Fatalf("expected an unknown key error, got %v", err)
+	}
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	cfg := Default()
+	cfg.Timezone = "Nowhere/Special"
+	cfg.Publishers = []string{"mqtt", "pigeon"}
+	cfg.GeofenceMaxAccuracy = -1
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("expected a validation error")
+	}
+
+	for _, key := range []string{"timezone:", "mqtt.url:", "pigeon", "geofence_max_accuracy:"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("expected %q in %v", key, err)
+		}
+	}
+}
+
+func TestApplySecretKeepsConfiguredValues(t *testing.T) {
+	cfg := Default()
+	cfg.Mqtt.Username = "configured"
+
+	cfg.ApplySecret(map[string]any{
+		"MQTT_USERNAME": "vault",
+		"MQTT_PASSWORD": "secret",
+	})
+
+	if cfg.Mqtt.Username != "configured" || cfg.Mqtt.Password != "secret" {
+		t.Fatalf("unexpected mqtt settings %+v", cfg.Mqtt)
+	}
+}
+
+func TestExampleConfigIsValid(t *testing.T) {
+	t.Setenv("VAULT_APPROLE_ID", "role")
+	t.Setenv("VAULT_SECRET_ID", "secret")
+
+	cfg, err := Load("../../config.example.yaml", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = cfg.Validate()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
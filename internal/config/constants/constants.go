@@ -0,0 +1,60 @@
+package constants
+
+import "time"
+
+const (
+	DEFAULT_CONFIG_FILE = "config.yaml"
+)
+
+const (
+	DEFAULT_LOG_LEVEL     = "info"
+	DEFAULT_TIMEZONE      = "Australia/Melbourne"
+	DEFAULT_CRON_SCHEDULE = "*/20 * * * *" // Every 20 minutes
+
+	DEFAULT_SECRETS_PROVIDER   = SECRETS_PROVIDER_VAULT
+	DEFAULT_SECRETS_DIRECTORY  = "/run/secrets"
+	DEFAULT_SECRETS_ENV_PREFIX = "FINDMY_"
+
+	DEFAULT_VAULT_MOUNT = "kv"
+	DEFAULT_VAULT_PATH  = "go-findmy"
+
+	DEFAULT_HISTORY_FILE      = ".storage/history.jsonl"
+	DEFAULT_HISTORY_MAX_AGE   = 30 * 24 * time.Hour
+	DEFAULT_DEDUP_CAPACITY    = 4096
+	DEFAULT_DEDUP_TTL         = 24 * time.Hour
+	DEFAULT_SELECTION_POLICY  = "latest"
+	DEFAULT_SELECTION_WINDOW  = 10 * time.Minute
+	DEFAULT_API_ADDRESS       = ":8080"
+	DEFAULT_MQTT_STALE_AFTER  = 24 * time.Hour
+	DEFAULT_PUBLISH_FILE      = ".storage/reports.jsonl"
+	DEFAULT_WEBHOOK_SPOOL_DIR = ".storage/webhook"
+	DEFAULT_OWNTRACKS_MODE    = OWNTRACKS_MODE_MQTT
+	DEFAULT_OWNTRACKS_USER    = "findmy"
+
+	DEFAULT_GEOFENCE_MAX_ACCURACY = 250.0
+)
+
+var (
+	DEFAULT_PUBLISHERS = []string{PUBLISHER_MQTT}
+)
+
+const (
+	SECRETS_PROVIDER_VAULT     = "vault"
+	SECRETS_PROVIDER_FILE      = "file"
+	SECRETS_PROVIDER_ENV       = "env"
+	SECRETS_PROVIDER_DIRECTORY = "directory"
+)
+
+const (
+	PUBLISHER_MQTT      = "mqtt"
+	PUBLISHER_STDOUT    = "stdout"
+	PUBLISHER_FILE      = "file"
+	PUBLISHER_WEBHOOK   = "webhook"
+	PUBLISHER_OWNTRACKS = "owntracks"
+	PUBLISHER_TRACCAR   = "traccar"
+)
+
+const (
+	OWNTRACKS_MODE_MQTT = "mqtt"
+	OWNTRACKS_MODE_HTTP = "http"
+)
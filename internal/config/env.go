@@ -0,0 +1,232 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dylanmazurek/go-findmy/internal/config/constants"
+)
+
+// applyEnv overrides the config with any of the environment variables the
+// service has always read
+func (c *Config) applyEnv() error {
+	envString("LOG_LEVEL", &c.LogLevel)
+	envString("TIMEZONE", &c.Timezone)
+	envString("CRON_SCHEDULE", &c.CronSchedule)
+
+	envString("SECRETS_PROVIDER", &c.Secrets.Provider)
+	envString("SECRETS_FILE", &c.Secrets.File)
+	envString("SECRETS_DIR", &c.Secrets.Directory)
+	envString("SECRETS_ENV_PREFIX", &c.Secrets.EnvPrefix)
+
+	envString("VAULT_ADDR", &c.Vault.Address)
+	envString("VAULT_APPROLE_ID", &c.Vault.AppRoleId)
+	envString("VAULT_SECRET_ID", &c.Vault.SecretId)
+	envString("VAULT_MOUNT", &c.Vault.Mount)
+	envString("VAULT_PATH", &c.Vault.Path)
+
+	envString("HISTORY_FILE", &c.History.File)
+	envString("DEDUP_FILE", &c.Dedup.File)
+
+	envString("API_ADDR", &c.Api.Address)
+	envString("API_TOKEN", &c.Api.Token)
+
+	envList("PUBLISHERS", &c.Publishers)
+
+	err := envPublishMqtt(&c.Publishers)
+	if err != nil {
+		return err
+	}
+
+	envString("MQTT_URL", &c.Mqtt.Url)
+	envString("MQTT_USERNAME", &c.Mqtt.Username)
+	envString("MQTT_PASSWORD", &c.Mqtt.Password)
+	envString("MQTT_CONFIGURATION_URL", &c.Mqtt.ConfigurationUrl)
+
+	envString("PUBLISH_FILE", &c.File.Path)
+
+	envString("WEBHOOK_URL", &c.Webhook.Url)
+	envString("WEBHOOK_SECRET", &c.Webhook.Secret)
+	envString("WEBHOOK_SPOOL_DIR", &c.Webhook.SpoolDir)
+
+	envString("OWNTRACKS_MODE", &c.OwnTracks.Mode)
+	envString("OWNTRACKS_URL", &c.OwnTracks.Url)
+	envString("OWNTRACKS_USER", &c.OwnTracks.User)
+	envString("OWNTRACKS_USERNAME", &c.OwnTracks.Username)
+	envString("OWNTRACKS_PASSWORD", &c.OwnTracks.Password)
+
+	envString("TRACCAR_URL", &c.Traccar.Url)
+
+	err = envInt("DEDUP_CAPACITY", &c.Dedup.Capacity)
+	if err != nil {
+		return err
+	}
+
+	err = envDuration("HISTORY_MAX_AGE", &c.History.MaxAge)
+	if err != nil {
+		return err
+	}
+
+	err = envDuration("DEDUP_TTL", &c.Dedup.TTL)
+	if err != nil {
+		return err
+	}
+
+	err = envDuration("MQTT_STALE_AFTER", &c.Mqtt.StaleAfter)
+	if err != nil {
+		return err
+	}
+
+	envString("SELECTION_POLICY", &c.Selection.Policy)
+
+	err = envDuration("SELECTION_WINDOW", &c.Selection.Window)
+	if err != nil {
+		return err
+	}
+
+	err = envFloat("SELECTION_MAX_SPEED", &c.Selection.MaxSpeed)
+	if err != nil {
+		return err
+	}
+
+	err = envFloat("GEOFENCE_MAX_ACCURACY", &c.GeofenceMaxAccuracy)
+	if err != nil {
+		return err
+	}
+
+	return envMap("TRACCAR_DEVICE_IDS", c.Traccar.DeviceIds)
+}
+
+// envPublishMqtt maps the deprecated PUBLISH_MQTT flag onto the publishers,
+// adding the mqtt publisher when true and removing it when false
+func envPublishMqtt(target *[]string) error {
+	value, ok := os.LookupEnv("PUBLISH_MQTT")
+	if !ok {
+		return nil
+	}
+
+	publishMqtt, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("PUBLISH_MQTT: expected true or false, got %q", value)
+	}
+
+	isMqtt := func(publisher string) bool {
+		return strings.EqualFold(strings.TrimSpace(publisher), constants.PUBLISHER_MQTT)
+	}
+
+	publishers := slices.Clone(*target)
+	switch {
+	case publishMqtt && !slices.ContainsFunc(publishers, isMqtt):
+		publishers = append(publishers, constants.PUBLISHER_MQTT)
+	case !publishMqtt:
+		publishers = slices.DeleteFunc(publishers, isMqtt)
+	}
+
+	if len(publishers) == 0 {
+		return fmt.Errorf("PUBLISH_MQTT is deprecated and false leaves no publishers, set PUBLISHERS instead")
+	}
+
+	*target = publishers
+
+	return nil
+}
+
+func envString(key string, target *string) {
+	value, ok := os.LookupEnv(key)
+	if ok {
+		*target = value
+	}
+}
+
+// envList reads a comma separated list
+func envList(key string, target *[]string) {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return
+	}
+
+	var values []string
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			values = append(values, item)
+		}
+	}
+
+	*target = values
+}
+
+func envInt(key string, target *int) error {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return nil
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("%s: expected an integer, got %q", key, value)
+	}
+
+	*target = parsed
+
+	return nil
+}
+
+func envFloat(key string, target *float64) error {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return nil
+	}
+
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fmt.Errorf("%s: expected a number, got %q", key, value)
+	}
+
+	*target = parsed
+
+	return nil
+}
+
+func envDuration(key string, target *time.Duration) error {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return nil
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("%s: expected a duration such as 24h, got %q", key, value)
+	}
+
+	*target = parsed
+
+	return nil
+}
+
+// envMap reads a comma separated list of key=value pairs
+func envMap(key string, target map[string]string) error {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return nil
+	}
+
+	for _, pair := range strings.Split(value, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+
+		mapKey, mapValue, ok := strings.Cut(pair, "=")
+		if !ok {
+			return fmt.Errorf("%s: expected key=value pairs, got %q", key, pair)
+		}
+
+		target[strings.TrimSpace(mapKey)] = strings.TrimSpace(mapValue)
+	}
+
+	return nil
+}
@@ -0,0 +1,190 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dylanmazurek/go-findmy/internal/config/constants"
+	"github.com/dylanmazurek/go-findmy/pkg/selection"
+	"github.com/go-co-op/gocron/v2"
+	"github.com/rs/zerolog"
+)
+
+// Validate checks every setting and returns all problems at once, each
+// prefixed with the YAML key it relates to
+func (c *Config) Validate() error {
+	var errs []error
+
+	fail := func(key string, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+	}
+
+	_, err := zerolog.ParseLevel(c.LogLevel)
+	if err != nil || c.LogLevel == "" {
+		fail("log_level", "unknown level %q, expected trace, debug, info, warn or error", c.LogLevel)
+	}
+
+	_, err = time.LoadLocation(c.Timezone)
+	if err != nil {
+		fail("timezone", "unknown timezone %q", c.Timezone)
+	}
+
+	err = validateCron(c.CronSchedule)
+	if err != nil {
+		fail("cron_schedule", "invalid schedule %q: %s", c.CronSchedule, err)
+	}
+
+	c.validateSecrets(fail)
+
+	if c.History.MaxAge < 0 {
+		fail("history.max_age", "must not be negative")
+	}
+
+	if c.Dedup.Capacity <= 0 {
+		fail("dedup.capacity", "must be greater than zero")
+	}
+
+	if c.Dedup.TTL <= 0 {
+		fail("dedup.ttl", "must be greater than zero")
+	}
+
+	_, err = selection.ParsePolicy(c.Selection.Policy)
+	if err != nil {
+		fail("selection.policy", "unknown policy %q, expected latest, accurate, preferred or fused", c.Selection.Policy)
+	}
+
+	if c.Selection.Window < 0 {
+		fail("selection.window", "must not be negative")
+	}
+
+	if c.Selection.MaxSpeed < 0 {
+		fail("selection.max_speed", "must not be negative")
+	}
+
+	if len(c.Publishers) == 0 {
+		fail("publishers", "at least one publisher is required")
+	}
+
+	for i, publisher := range c.Publishers {
+		publisher = strings.ToLower(strings.TrimSpace(publisher))
+		c.Publishers[i] = publisher
+
+		switch publisher {
+		case constants.PUBLISHER_MQTT:
+			if c.Mqtt.Url == "" {
+				fail("mqtt.url", "required by the mqtt publisher")
+			}
+
+			if c.Mqtt.StaleAfter < 0 {
+				fail("mqtt.stale_after", "must not be negative")
+			}
+		case constants.PUBLISHER_STDOUT:
+		case constants.PUBLISHER_FILE:
+			if c.File.Path == "" {
+				fail("file.path", "required by the file publisher")
+			}
+		case constants.PUBLISHER_WEBHOOK:
+			// the url may still come from the Vault secret
+			if c.Webhook.Url != "" {
+				validateUrl(fail, "webhook.url", c.Webhook.Url)
+			}
+		case constants.PUBLISHER_OWNTRACKS:
+			c.validateOwnTracks(fail)
+		case constants.PUBLISHER_TRACCAR:
+			if c.Traccar.Url != "" {
+				validateUrl(fail, "traccar.url", c.Traccar.Url)
+			}
+		default:
+			fail("publishers", "unknown publisher %q", publisher)
+		}
+	}
+
+	for i := range c.Geofences {
+		err := c.Geofences[i].Validate()
+		if err != nil {
+			fail(fmt.Sprintf("geofences[%d]", i), "%s", err)
+		}
+	}
+
+	if c.GeofenceMaxAccuracy < 0 {
+		fail("geofence_max_accuracy", "must not be negative")
+	}
+
+	err = errors.Join(errs...)
+	if err != nil {
+		return fmt.Errorf("invalid config:\n%w", err)
+	}
+
+	return nil
+}
+
+func (c *Config) validateSecrets(fail func(key string, format string, args ...any)) {
+	switch c.Secrets.Provider {
+	case constants.SECRETS_PROVIDER_VAULT:
+		if c.Vault.Address == "" {
+			fail("vault.address", "required by the vault secrets provider")
+		}
+
+		if c.Vault.AppRoleId == "" || c.Vault.SecretId == "" {
+			fail("vault", "approle_id and secret_id are required by the vault secrets provider")
+		}
+
+		if c.Vault.Mount == "" || c.Vault.Path == "" {
+			fail("vault", "mount and path must not be empty")
+		}
+	case constants.SECRETS_PROVIDER_FILE:
+		if c.Secrets.File == "" {
+			fail("secrets.file", "required by the file secrets provider")
+		}
+	case constants.SECRETS_PROVIDER_ENV:
+		if c.Secrets.EnvPrefix == "" {
+			fail("secrets.env_prefix", "required by the env secrets provider")
+		}
+	case constants.SECRETS_PROVIDER_DIRECTORY:
+		if c.Secrets.Directory == "" {
+			fail("secrets.directory", "required by the directory secrets provider")
+		}
+	default:
+		fail("secrets.provider", "unknown provider %q, expected vault, file, env or directory", c.Secrets.Provider)
+	}
+}
+
+func (c *Config) validateOwnTracks(fail func(key string, format string, args ...any)) {
+	switch c.OwnTracks.Mode {
+	case constants.OWNTRACKS_MODE_MQTT:
+		if c.OwnTracks.Url == "" && c.Mqtt.Url == "" {
+			fail("owntracks.url", "required by the owntracks publisher when mqtt.url is not set")
+		}
+	case constants.OWNTRACKS_MODE_HTTP:
+		if c.OwnTracks.Url == "" {
+			fail("owntracks.url", "required by the owntracks publisher in http mode")
+		} else {
+			validateUrl(fail, "owntracks.url", c.OwnTracks.Url)
+		}
+	default:
+		fail("owntracks.mode", "unknown mode %q, expected mqtt or http", c.OwnTracks.Mode)
+	}
+}
+
+func validateUrl(fail func(key string, format string, args ...any), key string, value string) {
+	u, err := url.ParseRequestURI(value)
+	if err != nil || u.Host == "" {
+		fail(key, "invalid url %q", value)
+	}
+}
+
+// validateCron checks a cron schedule by creating a throwaway job with it
+func validateCron(schedule string) error {
+	scheduler, err := gocron.NewScheduler()
+	if err != nil {
+		return err
+	}
+	defer scheduler.Shutdown()
+
+	_, err = scheduler.NewJob(gocron.CronJob(schedule, false), gocron.NewTask(func() {}))
+
+	return err
+}
@@ -0,0 +1,38 @@
+package findmy
+
+import (
+	"context"
+
+	"github.com/dylanmazurek/go-findmy/pkg/nova/models/protos/bindings"
+	"github.com/rs/zerolog/log"
+)
+
+func (s *Service) LocateDevice(ctx context.Context, uniqueId string) error {
+	log := log.Ctx(ctx)
+
+	log.Debug().Str("unique_id", uniqueId).Msg("locating device")
+
+	return s.novaClient.LocateTracker(ctx, uniqueId)
+}
+
+func (s *Service) PlaySound(ctx context.Context, uniqueId string, component bindings.DeviceComponent) error {
+	log := log.Ctx(ctx)
+
+	log.Debug().
+		Str("unique_id", uniqueId).
+		Str("component", component.String()).
+		Msg("playing sound on device")
+
+	return s.novaClient.PlaySound(ctx, uniqueId, component)
+}
+
+func (s *Service) StopSound(ctx context.Context, uniqueId string, component bindings.DeviceComponent) error {
+	log := log.Ctx(ctx)
+
+	log.Debug().
+		Str("unique_id", uniqueId).
+		Str("component", component.String()).
+		Msg("stopping sound on device")
+
+	return s.novaClient.StopSound(ctx, uniqueId, component)
+}
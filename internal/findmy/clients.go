@@ -2,15 +2,15 @@ package findmy
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"os"
 
-	"github.com/dylanmazurek/go-findmy/internal/publisher"
+	"github.com/dylanmazurek/go-findmy/internal/api"
+	"github.com/dylanmazurek/go-findmy/internal/config"
+	"github.com/dylanmazurek/go-findmy/pkg/dedup"
+	"github.com/dylanmazurek/go-findmy/pkg/geofence"
+	"github.com/dylanmazurek/go-findmy/pkg/history"
 	"github.com/dylanmazurek/go-findmy/pkg/notifier"
 	"github.com/dylanmazurek/go-findmy/pkg/nova"
-	shared "github.com/dylanmazurek/go-findmy/pkg/shared/models"
-	"github.com/dylanmazurek/go-findmy/pkg/shared/vault"
+	"github.com/dylanmazurek/go-findmy/pkg/selection"
 	"github.com/rs/zerolog/log"
 )
 
@@ -19,81 +19,113 @@ func (s *Service) initClients(ctx context.Context) error {
 
 	log.Trace().Msg("initializing clients")
 
-	vaultAddr := os.Getenv("VAULT_ADDR")
-	vaultAppRoleId := os.Getenv("VAULT_APPROLE_ID")
-	vaultSecretId := os.Getenv("VAULT_SECRET_ID")
-
-	vaultClient, err := vault.NewClient(ctx, vaultAddr, vaultAppRoleId, vaultSecretId)
+	secretProvider, err := NewSecretProvider(ctx, s.config)
 	if err != nil {
 		return err
 	}
 
-	vaultSecret, err := vaultClient.GetSecret(ctx, "kv", "go-findmy")
+	secret, err := secretProvider.GetSecret(ctx)
 	if err != nil {
 		return err
 	}
 
-	mqttUrl, hasMqttUrl := os.LookupEnv("MQTT_URL")
-	if !hasMqttUrl {
-		return fmt.Errorf("MQTT_URL not found in environment")
+	s.config.ApplySecret(secret)
+
+	publishers, mqttClient, err := initPublishers(ctx, s.config)
+	if err != nil {
+		return err
 	}
 
-	mqttUsername, ok := vaultSecret["MQTT_USERNAME"].(string)
-	if !ok {
-		return fmt.Errorf("MQTT_USERNAME not found in vault secret")
+	session, err := SessionFromSecret(secret)
+	if err != nil {
+		return err
 	}
 
-	mqttPassword, ok := vaultSecret["MQTT_PASSWORD"].(string)
-	if !ok {
-		return fmt.Errorf("MQTT_PASSWORD not found in vault secret")
+	semanticLocations, err := SemanticLocationsFromSecret(secret)
+	if err != nil {
+		return err
 	}
 
-	publisher, err := publisher.NewPublisher(ctx, mqttUrl, mqttUsername, mqttPassword)
+	historyStore, err := history.NewFileStore(ctx, s.config.History.File,
+		history.WithMaxAge(s.config.History.MaxAge),
+	)
 	if err != nil {
 		return err
 	}
 
-	sessionIrf, ok := vaultSecret["SESSION"].(map[string]any)
-	if !ok {
-		return fmt.Errorf("SESSION not found in vault secret")
+	dedupOpts := []dedup.Option{
+		dedup.WithCapacity(s.config.Dedup.Capacity),
+		dedup.WithTTL(s.config.Dedup.TTL),
 	}
 
-	sessionBytes, err := json.Marshal(sessionIrf)
-	if err != nil {
-		return err
+	if s.config.Dedup.File != "" {
+		dedupOpts = append(dedupOpts, dedup.WithPersistence(s.config.Dedup.File))
 	}
 
-	var session *notifier.Session
-	err = json.Unmarshal([]byte(sessionBytes), &session)
+	deduplicator, err := dedup.NewDeduplicator(ctx, dedupOpts...)
 	if err != nil {
 		return err
 	}
 
-	semanticLocationsIrf, ok := vaultSecret["SEMANTIC_LOCATIONS"].([]any)
-	if !ok {
-		return fmt.Errorf("SEMANTIC_LOCATIONS not found in vault secret")
+	geofences := s.config.Geofences
+	geofencesIrf, hasGeofences := secret["GEOFENCES"].([]any)
+	if len(geofences) == 0 && hasGeofences {
+		err := remarshal(geofencesIrf, &geofences)
+		if err != nil {
+			return err
+		}
 	}
 
-	semanticLocationsBytes, err := json.Marshal(semanticLocationsIrf)
+	geofenceEngine, err := geofence.NewEngine(geofences,
+		geofence.WithMaxAccuracy(s.config.GeofenceMaxAccuracy),
+	)
 	if err != nil {
 		return err
 	}
 
-	var semanticLocations []shared.SemanticLocation
-	err = json.Unmarshal([]byte(semanticLocationsBytes), &semanticLocations)
+	selector, err := newSelector(s.config.Selection)
 	if err != nil {
 		return err
 	}
 
-	notifierClient, err := notifier.NewClient(ctx, *session, publisher, semanticLocations)
+	if s.config.Api.Token != "" {
+		apiOpts := []api.Option{
+			api.WithToken(s.config.Api.Token),
+			api.WithAddress(s.config.Api.Address),
+		}
+
+		apiServer, err := api.NewServer(ctx, s, apiOpts...)
+		if err != nil {
+			return err
+		}
+
+		s.apiServer = apiServer
+	}
+
+	sessionSaver := NewSessionSaver(secretProvider)
+
+	notifierOpts := []notifier.Option{
+		notifier.WithSessionSaver(sessionSaver),
+		notifier.WithPublisher(publishers),
+		notifier.WithHistory(historyStore),
+		notifier.WithDeduplicator(deduplicator),
+		notifier.WithGeofences(geofenceEngine),
+		notifier.WithSelector(selector),
+		notifier.WithSemanticLocations(semanticLocations),
+	}
+
+	if s.apiServer != nil {
+		notifierOpts = append(notifierOpts, notifier.WithReportListener(s.apiServer.PublishReport))
+	}
+
+	notifierClient, err := notifier.NewClient(ctx, *session, notifierOpts...)
 	if err != nil {
 		return err
 	}
 
-	session.FcmSession.RegistrationToken = notifierClient.GetFcmToken()
-
+	// nova shares the notifier's session so both write back the same state
 	clientOps := []nova.Option{
-		nova.WithNotifierSession(session),
+		nova.WithSession(notifierClient.SharedSession()),
 	}
 
 	novaClient, err := nova.NewClient(ctx, clientOps...)
@@ -103,9 +135,28 @@ func (s *Service) initClients(ctx context.Context) error {
 
 	log.Trace().Msg("clients initialized")
 
+	s.secretProvider = secretProvider
 	s.novaClient = novaClient
 	s.notifierClient = notifierClient
-	s.publisherClient = publisher
+	s.publisher = publishers
+	s.mqttClient = mqttClient
+	s.historyStore = historyStore
+	s.deduplicator = deduplicator
+	s.geofenceEngine = geofenceEngine
 
 	return nil
 }
+
+// newSelector creates the report selection policy, with the max speed
+// converted from km/h
+func newSelector(cfg config.SelectionConfig) (selection.Selector, error) {
+	policy, err := selection.ParsePolicy(cfg.Policy)
+	if err != nil {
+		return nil, err
+	}
+
+	return selection.New(policy,
+		selection.WithWindow(cfg.Window),
+		selection.WithMaxSpeed(cfg.MaxSpeed/3.6),
+	)
+}
@@ -0,0 +1,24 @@
+package findmy
+
+import (
+	"context"
+	"fmt"
+
+	pubModels "github.com/dylanmazurek/go-findmy/internal/publisher/models"
+	"github.com/dylanmazurek/go-findmy/pkg/nova/models/protos/bindings"
+)
+
+func (s *Service) HandleCommand(ctx context.Context, uniqueId string, command pubModels.Command) error {
+	switch command {
+	case pubModels.CommandLocate:
+		return s.LocateDevice(ctx, uniqueId)
+	case pubModels.CommandRing:
+		return s.PlaySound(ctx, uniqueId, bindings.DeviceComponent_DEVICE_COMPONENT_UNSPECIFIED)
+	case pubModels.CommandStopRing:
+		return s.StopSound(ctx, uniqueId, bindings.DeviceComponent_DEVICE_COMPONENT_UNSPECIFIED)
+	case pubModels.CommandRefresh:
+		return s.RefreshDevices(ctx)
+	}
+
+	return fmt.Errorf("unsupported command: %s", command)
+}
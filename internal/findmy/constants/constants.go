@@ -1,10 +1,17 @@
 package constants
 
+import "time"
+
 const (
 	SERVICE_NAME = "find-my"
 )
 
 const (
-	DEFAULT_TIMEZONE      = "Australia/Melbourne"
-	DEFAULT_CRON_SCHEDULE = "*/20 * * * *" // Every 20 minutes
+	// SHUTDOWN_TIMEOUT bounds how long stopping the service waits for
+	// in-flight work and for publishers to flush
+	SHUTDOWN_TIMEOUT = 30 * time.Second
+
+	// PUBLISHER_CLOSE_TIMEOUT bounds closing the publishers, separate from
+	// SHUTDOWN_TIMEOUT so they can still mark the service offline
+	PUBLISHER_CLOSE_TIMEOUT = 10 * time.Second
 )
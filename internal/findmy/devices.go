@@ -2,10 +2,13 @@ package findmy
 
 import (
 	"context"
+	"time"
 
 	"github.com/dylanmazurek/go-findmy/internal"
+	"github.com/dylanmazurek/go-findmy/internal/api"
 	pubModels "github.com/dylanmazurek/go-findmy/internal/publisher/models"
 	"github.com/dylanmazurek/go-findmy/pkg/nova/models/protos/bindings"
+	shared "github.com/dylanmazurek/go-findmy/pkg/shared/models"
 	"github.com/rs/zerolog/log"
 )
 
@@ -15,6 +18,14 @@ func (s *Service) GetDevices(ctx context.Context) ([]pubModels.Device, error) {
 		return nil, err
 	}
 
+	return NewDevices(ctx, devices), nil
+}
+
+// NewDevices converts a nova device list to publisher devices, skipping any
+// without a unique id
+func NewDevices(ctx context.Context, devices *bindings.DevicesList) []pubModels.Device {
+	log := log.Ctx(ctx)
+
 	var pubDevices []pubModels.Device
 	for _, device := range devices.DeviceMetadata {
 		deviceName := device.GetUserDefinedDeviceName()
@@ -26,12 +37,17 @@ func (s *Service) GetDevices(ctx context.Context) ([]pubModels.Device, error) {
 			continue
 		}
 
-		var model, manufacturer string
+		var model, manufacturer, spotType string
 
 		switch deviceType {
 		case bindings.IdentifierInformationType_IDENTIFIER_SPOT:
-			model = device.GetInformation().GetDeviceRegistration().GetModel()
-			manufacturer = device.GetInformation().GetDeviceRegistration().GetManufacturer()
+			deviceRegistration := device.GetInformation().GetDeviceRegistration()
+
+			model = deviceRegistration.GetModel()
+			manufacturer = deviceRegistration.GetManufacturer()
+			spotType = internal.FormatSpotDeviceType(deviceRegistration.GetDeviceTypeInformation().GetDeviceType())
+		case bindings.IdentifierInformationType_IDENTIFIER_ANDROID:
+			spotType = "android"
 		}
 
 		serial, err := internal.FormatUniqueId(device)
@@ -40,24 +56,80 @@ func (s *Service) GetDevices(ctx context.Context) ([]pubModels.Device, error) {
 		}
 
 		newPubDevice := pubModels.NewDevice(deviceName, *serial, model, manufacturer)
+		newPubDevice.DeviceType = spotType
+		newPubDevice.EntityPicture = device.GetImageInformation().GetImageUrl()
+		newPubDevice.Access = newAccess(device.GetInformation().GetAccessInformation())
+
 		pubDevices = append(pubDevices, newPubDevice)
 	}
 
-	return pubDevices, nil
+	return pubDevices
+}
+
+// newAccess summarises who a device is shared with, or nil when the device
+// list has no access information
+func newAccess(accessInformation []*bindings.AccessInformation) *pubModels.Access {
+	if len(accessInformation) == 0 {
+		return nil
+	}
+
+	newAccess := &pubModels.Access{}
+	for _, access := range accessInformation {
+		if !access.GetHasAccess() {
+			continue
+		}
+
+		if access.GetIsOwner() {
+			newAccess.Owner = access.GetEmail()
+			newAccess.IsOwner = access.GetThisAccount()
+
+			continue
+		}
+
+		newAccess.SharedWith = append(newAccess.SharedWith, access.GetEmail())
+	}
+
+	return newAccess
 }
 
 func (s *Service) PublishDevice(ctx context.Context, device pubModels.Device) error {
 	log := log.Ctx(ctx)
 
-	resp, err := s.publisherClient.AddDevice(ctx, device)
+	err := s.publisher.PublishDevice(ctx, device)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to publish device")
 		return err
 	}
 
-	if resp == nil {
-		log.Error().Msg("response is nil")
+	return nil
+}
+
+func (s *Service) RefreshDevices(ctx context.Context) error {
+	return s.novaClient.RefreshDevices(ctx)
+}
+
+func (s *Service) LatestReport(ctx context.Context, uniqueId string) (*shared.LocationReport, error) {
+	if s.historyStore == nil {
+		return nil, api.ErrHistoryUnavailable
 	}
 
-	return nil
+	latestReports, err := s.historyStore.Latest(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	latestReport, ok := latestReports[uniqueId]
+	if !ok {
+		return nil, api.ErrNotFound
+	}
+
+	return &latestReport, nil
+}
+
+func (s *Service) History(ctx context.Context, uniqueId string, from time.Time, to time.Time) ([]shared.LocationReport, error) {
+	if s.historyStore == nil {
+		return nil, api.ErrHistoryUnavailable
+	}
+
+	return s.historyStore.Query(ctx, uniqueId, from, to)
 }
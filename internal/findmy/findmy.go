@@ -2,45 +2,67 @@ package findmy
 
 import (
 	"context"
-	"os"
-	"os/signal"
-	"syscall"
+	"errors"
+	"sync"
 	"time"
 
+	"github.com/dylanmazurek/go-findmy/internal/api"
+	"github.com/dylanmazurek/go-findmy/internal/config"
 	"github.com/dylanmazurek/go-findmy/internal/findmy/constants"
 	"github.com/dylanmazurek/go-findmy/internal/publisher"
+	"github.com/dylanmazurek/go-findmy/pkg/dedup"
+	"github.com/dylanmazurek/go-findmy/pkg/geofence"
+	"github.com/dylanmazurek/go-findmy/pkg/history"
 	"github.com/dylanmazurek/go-findmy/pkg/notifier"
 	"github.com/dylanmazurek/go-findmy/pkg/nova"
+	"github.com/dylanmazurek/go-findmy/pkg/shared/secrets"
 	"github.com/go-co-op/gocron/v2"
 	"github.com/rs/zerolog/log"
 )
 
 type Service struct {
-	novaClient      *nova.Client
-	notifierClient  *notifier.Client
-	publisherClient *publisher.Client
+	config         *config.Config
+	secretProvider secrets.SecretProvider
+
+	novaClient     *nova.Client
+	notifierClient *notifier.Client
+	publisher      *publisher.MultiPublisher
+	mqttClient     *publisher.Client
+	historyStore   history.Store
+	deduplicator   *dedup.Deduplicator
+	geofenceEngine *geofence.Engine
+	apiServer      *api.Server
 
 	internalScheduler gocron.Scheduler
+
+	mu      sync.Mutex
+	cancel  context.CancelFunc
+	stopped bool
+	stopErr error
+	done    chan struct{}
 }
 
-func NewService(ctx context.Context) (*Service, error) {
+func NewService(ctx context.Context, cfg *config.Config) (*Service, error) {
 	log := log.Ctx(ctx).With().Str("service", constants.SERVICE_NAME).Logger()
 
 	log.Debug().Msg("creating new find-my service")
 
-	var newFindMyService Service
-
-	err := newFindMyService.initClients(ctx)
+	err := cfg.Validate()
 	if err != nil {
 		return nil, err
 	}
 
-	timezoneEnv, hasTimezoneEnv := os.LookupEnv("TIMEZONE")
-	if !hasTimezoneEnv {
-		timezoneEnv = constants.DEFAULT_TIMEZONE
+	newFindMyService := Service{
+		config: cfg,
+		done:   make(chan struct{}),
 	}
 
-	timezone, err := time.LoadLocation(timezoneEnv)
+	err = newFindMyService.initClients(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	timezone, err := time.LoadLocation(cfg.Timezone)
 	if err != nil {
 		return nil, err
 	}
@@ -63,12 +85,7 @@ func NewService(ctx context.Context) (*Service, error) {
 func (s *Service) AddJobs(ctx context.Context) error {
 	log := log.Ctx(ctx)
 
-	cronSchedule, hasCronSchedule := os.LookupEnv("CRON_SCHEDULE")
-	if !hasCronSchedule {
-		cronSchedule = constants.DEFAULT_CRON_SCHEDULE
-	}
-
-	job := gocron.CronJob(cronSchedule, false)
+	job := gocron.CronJob(s.config.CronSchedule, false)
 	task := gocron.NewTask(func(ctx context.Context) {
 		err := s.novaClient.RefreshDevices(ctx)
 		if err != nil {
@@ -92,24 +109,77 @@ func (s *Service) AddJobs(ctx context.Context) error {
 	return nil
 }
 
+// Start connects to FCM and starts the API and scheduler in the background.
+// The service runs until Stop is called or ctx is cancelled
 func (s *Service) Start(ctx context.Context) error {
 	log := log.Ctx(ctx).With().Str("service", constants.SERVICE_NAME).Logger()
 
-	devices, err := s.GetDevices(ctx)
+	s.mu.Lock()
+	if s.cancel != nil || s.stopped {
+		s.mu.Unlock()
+		return errors.New("service already started")
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+	s.mu.Unlock()
+
+	go s.run(runCtx)
+
+	devices, err := s.GetDevices(runCtx)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to get devices")
 	}
 
-	s.publisherClient.InitalizeDevices(ctx, devices)
+	if s.mqttClient != nil && s.historyStore != nil {
+		// seed availability so recently seen devices aren't marked
+		// unavailable until their next report
+		latestReports, err := s.historyStore.Latest(runCtx)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to read latest reports")
+		}
+
+		for uniqueId, report := range latestReports {
+			s.mqttClient.SetLastSeen(uniqueId, report.ReportTime)
+		}
+	}
+
+	for _, device := range devices {
+		s.PublishDevice(runCtx, device)
+	}
+
+	if s.mqttClient != nil {
+		for _, device := range devices {
+			err := s.mqttClient.AddGeofenceSensors(runCtx, device, s.geofenceEngine.Geofences())
+			if err != nil {
+				log.Error().Err(err).Msg("failed to publish geofence sensors")
+			}
+		}
+
+		err = s.mqttClient.SubscribeCommands(runCtx, s.HandleCommand)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to subscribe to commands")
+		}
+	}
 
 	log.Trace().Msg("starting find-my service")
 
-	err = s.notifierClient.StartListening(ctx)
+	err = s.notifierClient.StartListening(runCtx)
 	if err != nil {
-		return err
+		return errors.Join(err, s.Stop(ctx))
 	}
 
-	s.AddJobs(ctx)
+	if s.apiServer != nil {
+		err = s.apiServer.Start(runCtx)
+		if err != nil {
+			return errors.Join(err, s.Stop(ctx))
+		}
+	}
+
+	err = s.AddJobs(runCtx)
+	if err != nil {
+		return errors.Join(err, s.Stop(ctx))
+	}
 
 	log.Debug().Msg("starting scheduler")
 
@@ -117,14 +187,102 @@ func (s *Service) Start(ctx context.Context) error {
 
 	log.Debug().Msg("scheduler started")
 
-	sigs := make(chan os.Signal, 1)
-	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
-
 	log.Info().Msg("find-my service running")
 
-	<-sigs
+	return nil
+}
+
+// Stop cancels the service and waits until it has shut down, or ctx is done
+func (s *Service) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	cancel := s.cancel
+	stopped := s.stopped
+	s.stopped = true
+	s.mu.Unlock()
+
+	if cancel == nil {
+		// never started, so there is no run loop to shut the clients down
+		if !stopped {
+			s.shutdown(ctx)
+		}
+
+		return s.stopErr
+	}
+
+	cancel()
+
+	select {
+	case <-s.done:
+		return s.stopErr
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Wait blocks until the service has shut down
+func (s *Service) Wait() error {
+	<-s.done
+
+	return s.stopErr
+}
+
+// run shuts the service down once its context is cancelled
+func (s *Service) run(ctx context.Context) {
+	<-ctx.Done()
+
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), constants.SHUTDOWN_TIMEOUT)
+	defer cancel()
+
+	s.shutdown(shutdownCtx)
+}
+
+// shutdown stops accepting work, waits for in-flight reports and then closes
+// the publishers and stores
+func (s *Service) shutdown(ctx context.Context) {
+	log := log.Ctx(ctx).With().Str("service", constants.SERVICE_NAME).Logger()
+
+	defer close(s.done)
+
+	log.Info().Msg("stopping find-my service")
+
+	var errs []error
+	if s.apiServer != nil {
+		errs = append(errs, s.apiServer.Shutdown(ctx))
+	}
 
-	log.Info().Msg("received terminate signal, stopping listener")
+	if s.internalScheduler != nil {
+		errs = append(errs, s.internalScheduler.Shutdown())
+	}
+
+	if s.notifierClient != nil && s.cancel != nil {
+		select {
+		case <-s.notifierClient.Done():
+		case <-ctx.Done():
+			errs = append(errs, errors.New("timed out waiting for reports to be handled"))
+		}
+	}
+
+	if s.publisher != nil {
+		// the shutdown context may have run out waiting for reports, the
+		// publishers get their own time to flush and publish offline
+		closeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), constants.PUBLISHER_CLOSE_TIMEOUT)
+		errs = append(errs, s.publisher.Close(closeCtx))
+		cancel()
+	}
+
+	if s.deduplicator != nil {
+		errs = append(errs, s.deduplicator.Save())
+	}
+
+	if s.historyStore != nil {
+		errs = append(errs, s.historyStore.Close())
+	}
+
+	s.stopErr = errors.Join(errs...)
+	if s.stopErr != nil {
+		log.Error().Err(s.stopErr).Msg("failed to stop find-my service cleanly")
+		return
+	}
 
-	return err
+	log.Info().Msg("find-my service stopped")
 }
@@ -0,0 +1,77 @@
+package findmy
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dylanmazurek/go-findmy/internal/api"
+	"github.com/dylanmazurek/go-findmy/pkg/history"
+)
+
+func TestStopBeforeStartClosesStores(t *testing.T) {
+	ctx := context.Background()
+
+	historyStore := history.NewMemoryStore()
+	s := &Service{
+		historyStore: historyStore,
+		done:         make(chan struct{}),
+	}
+
+	err := s.Stop(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = s.Wait()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = historyStore.Latest(ctx)
+	if !errors.Is(err, history.ErrStoreClosed) {
+		t.Fatalf("expected history store to be closed, got %v", err)
+	}
+
+	err = s.Stop(ctx)
+	if err != nil {
+		t.Fatalf("expected a second stop to succeed, got %v", err)
+	}
+
+	err = s.Start(ctx)
+	if err == nil {
+		t.Fatal("expected start after stop to fail")
+	}
+}
+
+func TestRunShutsDownWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	s := &Service{done: make(chan struct{})}
+
+	go s.run(ctx)
+	cancel()
+
+	select {
+	case <-s.done:
+	case <-time.After(time.Second):
+		t.Fatal("expected service to shut down when its context is cancelled")
+	}
+}
+
+func TestHistoryUnavailableWithoutStore(t *testing.T) {
+	ctx := context.Background()
+
+	s := &Service{}
+
+	_, err := s.LatestReport(ctx, "tracker")
+	if !errors.Is(err, api.ErrHistoryUnavailable) {
+		t.Fatalf("expected history unavailable, got %v", err)
+	}
+
+	_, err = s.History(ctx, "tracker", time.Time{}, time.Time{})
+	if !errors.Is(err, api.ErrHistoryUnavailable) {
+		t.Fatalf("expected history unavailable, got %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+package findmy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dylanmazurek/go-findmy/internal/config"
+	"github.com/dylanmazurek/go-findmy/internal/config/constants"
+	"github.com/dylanmazurek/go-findmy/internal/publisher"
+	"github.com/dylanmazurek/go-findmy/internal/publisher/jsonl"
+	"github.com/dylanmazurek/go-findmy/internal/publisher/owntracks"
+	"github.com/dylanmazurek/go-findmy/internal/publisher/traccar"
+	"github.com/dylanmazurek/go-findmy/internal/publisher/webhook"
+)
+
+// initPublishers creates every configured publisher. The MQTT client is also
+// returned on its own as it handles commands and geofence discovery.
+func initPublishers(ctx context.Context, cfg *config.Config) (*publisher.MultiPublisher, *publisher.Client, error) {
+	var publishers []publisher.Publisher
+	var mqttClient *publisher.Client
+
+	closeAll := func() {
+		publisher.NewMultiPublisher(publishers...).Close(ctx)
+	}
+
+	for _, name := range cfg.Publishers {
+		var newPublisher publisher.Publisher
+		var err error
+
+		switch name {
+		case constants.PUBLISHER_MQTT:
+			mqttClient, err = newMqttPublisher(ctx, cfg.Mqtt)
+			newPublisher = mqttClient
+		case constants.PUBLISHER_STDOUT:
+			newPublisher = jsonl.NewStdoutPublisher()
+		case constants.PUBLISHER_FILE:
+			newPublisher, err = jsonl.NewFilePublisher(cfg.File.Path)
+		case constants.PUBLISHER_WEBHOOK:
+			newPublisher, err = newWebhookPublisher(ctx, cfg.Webhook)
+		case constants.PUBLISHER_OWNTRACKS:
+			newPublisher, err = newOwntracksPublisher(ctx, cfg.OwnTracks, cfg.Mqtt)
+		case constants.PUBLISHER_TRACCAR:
+			newPublisher, err = newTraccarPublisher(ctx, cfg.Traccar)
+		default:
+			err = fmt.Errorf("unknown publisher: %s", name)
+		}
+
+		if err != nil {
+			closeAll()
+			return nil, nil, err
+		}
+
+		publishers = append(publishers, newPublisher)
+	}
+
+	return publisher.NewMultiPublisher(publishers...), mqttClient, nil
+}
+
+func newMqttPublisher(ctx context.Context, cfg config.MqttConfig) (*publisher.Client, error) {
+	if cfg.Username == "" || cfg.Password == "" {
+		return nil, fmt.Errorf("mqtt.username and mqtt.password must be configured or set in the secret")
+	}
+
+	return publisher.NewPublisher(ctx, cfg.Url, cfg.Username, cfg.Password,
+		publisher.WithStaleAfter(cfg.StaleAfter),
+		publisher.WithConfigurationUrl(cfg.ConfigurationUrl),
+	)
+}
+
+func newWebhookPublisher(ctx context.Context, cfg config.WebhookConfig) (*webhook.Publisher, error) {
+	var webhookOpts []webhook.Option
+	if cfg.Secret != "" {
+		webhookOpts = append(webhookOpts, webhook.WithSecret(cfg.Secret))
+	}
+
+	if cfg.SpoolDir != "" {
+		webhookOpts = append(webhookOpts, webhook.WithSpoolDir(cfg.SpoolDir))
+	}
+
+	return webhook.NewPublisher(ctx, cfg.Url, webhookOpts...)
+}
+
+// newOwntracksPublisher publishes OwnTracks messages over MQTT, falling back
+// to the mqtt settings, or the OwnTracks HTTP mode
+func newOwntracksPublisher(ctx context.Context, cfg config.OwnTracksConfig, mqttCfg config.MqttConfig) (*owntracks.Publisher, error) {
+	owntracksOpts := []owntracks.Option{
+		owntracks.WithUser(cfg.User),
+	}
+
+	switch cfg.Mode {
+	case constants.OWNTRACKS_MODE_MQTT:
+		if cfg.Url == "" {
+			cfg.Url = mqttCfg.Url
+		}
+
+		if cfg.Username == "" {
+			cfg.Username = mqttCfg.Username
+			cfg.Password = mqttCfg.Password
+		}
+
+		if cfg.Username != "" {
+			owntracksOpts = append(owntracksOpts, owntracks.WithCredentials(cfg.Username, cfg.Password))
+		}
+
+		return owntracks.NewMqttPublisher(ctx, cfg.Url, owntracksOpts...)
+	case constants.OWNTRACKS_MODE_HTTP:
+		if cfg.Username != "" {
+			owntracksOpts = append(owntracksOpts, owntracks.WithCredentials(cfg.Username, cfg.Password))
+		}
+
+		return owntracks.NewHttpPublisher(ctx, cfg.Url, owntracksOpts...)
+	}
+
+	return nil, fmt.Errorf("unknown owntracks mode: %s", cfg.Mode)
+}
+
+func newTraccarPublisher(ctx context.Context, cfg config.TraccarConfig) (*traccar.Publisher, error) {
+	if cfg.Url == "" {
+		return nil, fmt.Errorf("traccar.url must be configured or set in the secret")
+	}
+
+	return traccar.NewPublisher(ctx, cfg.Url, traccar.WithDeviceIds(cfg.DeviceIds))
+}
@@ -0,0 +1,131 @@
+package findmy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/dylanmazurek/go-findmy/internal/config"
+	"github.com/dylanmazurek/go-findmy/internal/config/constants"
+	"github.com/dylanmazurek/go-findmy/pkg/notifier"
+	shared "github.com/dylanmazurek/go-findmy/pkg/shared/models"
+	"github.com/dylanmazurek/go-findmy/pkg/shared/secrets"
+	"github.com/rs/zerolog/log"
+)
+
+// NewSecretProvider creates the configured secret provider, logging in to
+// Vault when it is used
+func NewSecretProvider(ctx context.Context, cfg *config.Config) (secrets.SecretProvider, error) {
+	switch cfg.Secrets.Provider {
+	case constants.SECRETS_PROVIDER_VAULT:
+		if cfg.Vault.Address == "" {
+			return nil, fmt.Errorf("vault.address is not configured")
+		}
+
+		return secrets.NewVaultProvider(ctx, cfg.Vault.Address, cfg.Vault.AppRoleId, cfg.Vault.SecretId, cfg.Vault.Mount, cfg.Vault.Path)
+	case constants.SECRETS_PROVIDER_FILE:
+		return secrets.NewFileProvider(cfg.Secrets.File), nil
+	case constants.SECRETS_PROVIDER_ENV:
+		return secrets.NewEnvProvider(cfg.Secrets.EnvPrefix), nil
+	case constants.SECRETS_PROVIDER_DIRECTORY:
+		return secrets.NewDirectoryProvider(cfg.Secrets.Directory), nil
+	}
+
+	return nil, fmt.Errorf("unknown secrets provider: %s", cfg.Secrets.Provider)
+}
+
+// SessionFromSecret reads the notifier session from the SESSION key of a secret
+func SessionFromSecret(secret map[string]any) (*notifier.Session, error) {
+	sessionIrf, ok := secret["SESSION"].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("SESSION not found in secret")
+	}
+
+	var session *notifier.Session
+	err := remarshal(sessionIrf, &session)
+	if err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// NewSessionSaver writes sessions back to the SESSION key of the provider's
+// secret, keeping the other keys as they are. Read only providers are skipped
+// with a warning, as the session is then only kept in memory
+func NewSessionSaver(provider secrets.SecretProvider) notifier.SessionSaver {
+	var mu sync.Mutex
+
+	return func(ctx context.Context, session *notifier.Session) error {
+		log := log.Ctx(ctx)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		secret, err := provider.GetSecret(ctx)
+		if err != nil {
+			return err
+		}
+
+		sessionBytes, err := json.Marshal(session)
+		if err != nil {
+			return err
+		}
+
+		// decode with json.Number so the android id and security token keep
+		// their precision
+		decoder := json.NewDecoder(bytes.NewReader(sessionBytes))
+		decoder.UseNumber()
+
+		var sessionValue map[string]any
+		err = decoder.Decode(&sessionValue)
+		if err != nil {
+			return err
+		}
+
+		secret["SESSION"] = sessionValue
+
+		err = provider.PutSecret(ctx, secret)
+		if errors.Is(err, secrets.ErrReadOnly) {
+			log.Warn().Err(err).Msg("secret provider is read only, session changes will not be saved")
+			return nil
+		}
+
+		return err
+	}
+}
+
+// SemanticLocationsFromSecret reads the SEMANTIC_LOCATIONS key of a secret, a
+// missing key means no semantic locations are configured
+func SemanticLocationsFromSecret(secret map[string]any) ([]shared.SemanticLocation, error) {
+	value, ok := secret["SEMANTIC_LOCATIONS"]
+	if !ok || value == nil {
+		return []shared.SemanticLocation{}, nil
+	}
+
+	semanticLocationsIrf, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("SEMANTIC_LOCATIONS in secret is not a list")
+	}
+
+	var semanticLocations []shared.SemanticLocation
+	err := remarshal(semanticLocationsIrf, &semanticLocations)
+	if err != nil {
+		return nil, err
+	}
+
+	return semanticLocations, nil
+}
+
+// remarshal converts a decoded secret value into a typed value via JSON
+func remarshal(value any, target any) error {
+	valueBytes, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(valueBytes, target)
+}
@@ -0,0 +1,91 @@
+package findmy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dylanmazurek/go-findmy/pkg/notifier"
+	"github.com/dylanmazurek/go-findmy/pkg/notifier/models"
+	"github.com/dylanmazurek/go-findmy/pkg/shared/secrets"
+)
+
+func TestSessionSaverKeepsOtherKeys(t *testing.T) {
+	ctx := context.Background()
+
+	provider := secrets.NewMemoryProvider(map[string]any{
+		"MQTT_PASSWORD": "hunter2",
+		"SESSION":       map[string]any{"username": "someone"},
+	})
+
+	androidId := uint64(4439583726485123456)
+	registrationToken := "fcm-token"
+	expiresAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	session := &notifier.Session{
+		Username:  "someone",
+		AndroidId: &androidId,
+		FcmSession: &models.FcmSession{
+			RegistrationToken: &registrationToken,
+		},
+		AdmSession: &models.AdmSession{
+			AasToken:      "aas",
+			AuthToken:     "adm",
+			AuthExpiresAt: &expiresAt,
+		},
+	}
+
+	err := NewSessionSaver(provider)(ctx, session)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secret, err := provider.GetSecret(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if secret["MQTT_PASSWORD"] != "hunter2" {
+		t.Fatalf("expected MQTT_PASSWORD to be kept, got %v", secret["MQTT_PASSWORD"])
+	}
+
+	saved, err := SessionFromSecret(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if saved.AndroidId == nil || *saved.AndroidId != androidId {
+		t.Fatalf("expected android id %d, got %v", androidId, saved.AndroidId)
+	}
+
+	if *saved.FcmSession.RegistrationToken != registrationToken {
+		t.Fatalf("expected registration token %s, got %s", registrationToken, *saved.FcmSession.RegistrationToken)
+	}
+
+	if saved.AdmSession.AuthToken != "adm" || !saved.AdmSession.AuthExpiresAt.Equal(expiresAt) {
+		t.Fatalf("expected adm token to be saved, got %+v", saved.AdmSession)
+	}
+}
+
+func TestSessionSaverSkipsReadOnlyProvider(t *testing.T) {
+	err := NewSessionSaver(secrets.NewEnvProvider("FINDMY_TEST_"))(context.Background(), &notifier.Session{})
+	if err != nil {
+		t.Fatalf("expected read only provider to be skipped, got %v", err)
+	}
+}
+
+func TestSemanticLocationsFromSecretWithoutKey(t *testing.T) {
+	semanticLocations, err := SemanticLocationsFromSecret(map[string]any{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(semanticLocations) != 0 {
+		t.Fatalf("expected no semantic locations, got %d", len(semanticLocations))
+	}
+
+	_, err = SemanticLocationsFromSecret(map[string]any{"SEMANTIC_LOCATIONS": "home"})
+	if err == nil {
+		t.Fatal("expected an error for a value that is not a list")
+	}
+}
@@ -7,16 +7,11 @@ import (
 	"github.com/rs/zerolog"
 )
 
-func InitLogger(ctx context.Context) context.Context {
-	output := zerolog.ConsoleWriter{Out: os.Stdout, TimeFormat: "Mon, 02 Jan 2006 03:04:05"}
+func InitLogger(ctx context.Context, logLevelStr string) context.Context {
+	output := zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: "Mon, 02 Jan 2006 03:04:05"}
 
-	logLevelEnv, hasLogLevel := os.LookupEnv("LOG_LEVEL")
-	if !hasLogLevel {
-		logLevelEnv = "info"
-	}
-
-	logLevel, err := zerolog.ParseLevel(logLevelEnv)
-	if err != nil {
+	logLevel, err := zerolog.ParseLevel(logLevelStr)
+	if err != nil || logLevelStr == "" {
 		logLevel = zerolog.InfoLevel
 	}
 
@@ -0,0 +1,118 @@
+package publisher
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/dylanmazurek/go-findmy/internal/publisher/models"
+	shared "github.com/dylanmazurek/go-findmy/pkg/shared/models"
+)
+
+func TestReportMatchesAttributesSchema(t *testing.T) {
+	var schema struct {
+		Required   []string                   `json:"required"`
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+
+	err := json.Unmarshal(models.AttributesSchema, &schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	uniqueId := "tracker"
+	semanticName := "Home"
+
+	report := models.NewReport(shared.LocationReport{
+		UniqueId:     &uniqueId,
+		ReportType:   shared.ReportTypeLocation,
+		ReportTime:   now.Add(-90 * time.Second),
+		Status:       shared.ReportStatusCrowdsourced,
+		Latitude:     -33.86,
+		Longitude:    151.21,
+		Altitude:     12,
+		Accuracy:     25,
+		SemanticName: &semanticName,
+		ReportCount:  3,
+		IsOwnReport:  true,
+	}, &models.Access{Owner: "owner@example.com", IsOwner: true, SharedWith: []string{"friend@example.com"}}, now)
+
+	if report.Age != 90 {
+		t.Errorf("expected age of 90 seconds, got %d", report.Age)
+	}
+
+	reportJson, err := json.Marshal(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var attributes map[string]any
+	err = json.Unmarshal(reportJson, &attributes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for key := range attributes {
+		if _, ok := schema.Properties[key]; !ok {
+			t.Errorf("attribute %s is missing from the schema", key)
+		}
+	}
+
+	for key := range schema.Properties {
+		if _, ok := attributes[key]; !ok {
+			t.Errorf("schema property %s is never published", key)
+		}
+	}
+
+	// required attributes are published even when empty
+	emptyJson, err := json.Marshal(models.Report{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var emptyAttributes map[string]any
+	err = json.Unmarshal(emptyJson, &emptyAttributes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range schema.Required {
+		if _, ok := emptyAttributes[key]; !ok {
+			t.Errorf("required attribute %s is omitted when empty", key)
+		}
+	}
+
+	if attributes["schema_version"] != float64(models.ATTRIBUTES_SCHEMA_VERSION) {
+		t.Errorf("expected schema version %d, got %v", models.ATTRIBUTES_SCHEMA_VERSION, attributes["schema_version"])
+	}
+}
+
+func TestDeviceMessageOmitsDiscoveryFields(t *testing.T) {
+	device := models.NewDevice("Keys", "tracker", "Tag", "Pebblebee")
+	device.DeviceType = "Tracker"
+
+	messageJson, err := json.Marshal(models.NewDeviceMessage(device))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var message struct {
+		Device map[string]any `json:"device"`
+	}
+
+	err = json.Unmarshal(messageJson, &message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"availability", "state_topic", "json_attributes_topic", "device_class", "device"} {
+		if _, ok := message.Device[key]; ok {
+			t.Errorf("expected no %s in device message", key)
+		}
+	}
+
+	if message.Device["name"] != "Keys" || message.Device["device_type"] != "Tracker" {
+		t.Fatalf("unexpected device message %v", message.Device)
+	}
+}
@@ -0,0 +1,111 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/dylanmazurek/go-findmy/internal/publisher/constants"
+	"github.com/dylanmazurek/go-findmy/internal/publisher/models"
+	"github.com/eclipse/paho.golang/autopaho"
+	"github.com/eclipse/paho.golang/paho"
+	"github.com/rs/zerolog/log"
+)
+
+// SetLastSeen records when a device last reported, such as from history on
+// startup, so its availability is known before the next report arrives
+func (c *Client) SetLastSeen(uniqueId string, lastSeen time.Time) {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	if lastSeen.After(c.lastSeen[uniqueId]) {
+		c.lastSeen[uniqueId] = lastSeen
+	}
+}
+
+// isAvailable reports whether a device has reported within staleAfter
+func (c *Client) isAvailable(uniqueId string, now time.Time) bool {
+	if c.staleAfter == 0 {
+		return true
+	}
+
+	lastSeen, ok := c.lastSeen[uniqueId]
+	if !ok {
+		return false
+	}
+
+	return now.Sub(lastSeen) < c.staleAfter
+}
+
+// updateAvailability publishes the availability of every known device that
+// has changed, or of every device when force is set
+func (c *Client) updateAvailability(ctx context.Context, force bool) error {
+	now := time.Now()
+
+	changed := map[string]bool{}
+
+	c.stateMu.Lock()
+	for uniqueId := range c.devices {
+		available := c.isAvailable(uniqueId, now)
+
+		previous, known := c.available[uniqueId]
+		if force || !known || previous != available {
+			changed[uniqueId] = available
+		}
+
+		c.available[uniqueId] = available
+	}
+	c.stateMu.Unlock()
+
+	var errs []error
+	for uniqueId, available := range changed {
+		payload := constants.PAYLOAD_OFFLINE
+		if available {
+			payload = constants.PAYLOAD_ONLINE
+		}
+
+		errs = append(errs, c.publishRetained(ctx, c.internalClient, models.GetAvailabilityTopic(uniqueId), payload))
+	}
+
+	return errors.Join(errs...)
+}
+
+// staleLoop marks devices unavailable as their last report ages past
+// staleAfter
+func (c *Client) staleLoop(ctx context.Context) {
+	log := log.Ctx(ctx).With().Str("client", constants.SERVICE_NAME).Logger()
+
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(constants.STALE_CHECK_INTERVAL)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			err := c.updateAvailability(ctx, false)
+			if err != nil {
+				log.Error().Err(err).Msg("failed to publish device availability")
+			}
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+func (c *Client) publishAvailability(ctx context.Context, cm *autopaho.ConnectionManager, availability string) error {
+	return c.publishRetained(ctx, cm, constants.TOPIC_AVAILABILITY, availability)
+}
+
+func (c *Client) publishRetained(ctx context.Context, cm *autopaho.ConnectionManager, topic string, payload string) error {
+	publish := &paho.Publish{
+		QoS:     1,
+		Topic:   topic,
+		Retain:  true,
+		Payload: []byte(payload),
+	}
+
+	_, err := cm.Publish(ctx, publish)
+
+	return err
+}
@@ -0,0 +1,87 @@
+package publisher
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dylanmazurek/go-findmy/internal/publisher/constants"
+	"github.com/dylanmazurek/go-findmy/internal/publisher/models"
+)
+
+func TestDeviceAvailability(t *testing.T) {
+	now := time.Now()
+
+	c := &Client{
+		staleAfter: time.Hour,
+		lastSeen:   map[string]time.Time{},
+	}
+
+	if c.isAvailable("tracker", now) {
+		t.Fatal("expected device without reports to be unavailable")
+	}
+
+	c.SetLastSeen("tracker", now.Add(-10*time.Minute))
+	c.SetLastSeen("tracker", now.Add(-2*time.Hour))
+	if !c.isAvailable("tracker", now) {
+		t.Fatal("expected recently seen device to be available")
+	}
+
+	if c.isAvailable("tracker", now.Add(time.Hour)) {
+		t.Fatal("expected device to be unavailable once stale")
+	}
+
+	c.staleAfter = 0
+	if !c.isAvailable("other", now) {
+		t.Fatal("expected devices to always be available when staleness is disabled")
+	}
+}
+
+func TestDeviceDiscoveryAvailability(t *testing.T) {
+	device := models.NewDevice("Keys", "tracker", "Tag", "Google")
+
+	configJson, err := json.Marshal(device)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := string(configJson)
+	for _, expected := range []string{
+		constants.TOPIC_AVAILABILITY,
+		models.GetAvailabilityTopic("tracker"),
+		`"availability_mode":"all"`,
+	} {
+		if !strings.Contains(config, expected) {
+			t.Errorf("expected discovery config to contain %s, got %s", expected, config)
+		}
+	}
+}
+
+func TestDeviceSensors(t *testing.T) {
+	device := models.NewDevice("Keys", "tracker", "Tag", "Google")
+
+	sensors := models.NewSensors(device)
+
+	configTopics := map[string]models.Sensor{}
+	for _, sensor := range sensors {
+		configTopics[sensor.GetConfigTopic()] = sensor
+	}
+
+	for _, objectId := range []string{"last_seen", "accuracy", "source", "semantic_location", "device_type"} {
+		topic := "homeassistant/sensor/tracker/" + objectId + "/config"
+
+		sensor, ok := configTopics[topic]
+		if !ok {
+			t.Fatalf("expected a sensor published to %s", topic)
+		}
+
+		if sensor.DeviceInfo.ViaDevice != constants.BRIDGE_ID {
+			t.Errorf("expected %s to be linked via the bridge, got %q", objectId, sensor.DeviceInfo.ViaDevice)
+		}
+	}
+
+	if configTopics["homeassistant/sensor/tracker/device_type/config"].AvailabilityMode != "" {
+		t.Error("expected device type to only follow the bridge availability")
+	}
+}
@@ -1,5 +1,30 @@
 package constants
 
+import "time"
+
 const (
 	SERVICE_NAME = "publisher"
 )
+
+const (
+	// BRIDGE_ID identifies the go-findmy device in Home Assistant, which
+	// every tracker is connected via
+	BRIDGE_ID   = "findmy2mqtt"
+	BRIDGE_NAME = "go-findmy"
+)
+
+const (
+	TOPIC_COMMAND_SUBSCRIPTION = "findmy2mqtt/+/command"
+	TOPIC_AVAILABILITY         = "findmy2mqtt/availability"
+	TOPIC_HOMEASSISTANT_STATUS = "homeassistant/status"
+)
+
+const (
+	// STALE_CHECK_INTERVAL is how often device availability is re-evaluated
+	STALE_CHECK_INTERVAL = time.Minute
+)
+
+const (
+	PAYLOAD_ONLINE  = "online"
+	PAYLOAD_OFFLINE = "offline"
+)
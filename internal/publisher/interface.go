@@ -0,0 +1,21 @@
+package publisher
+
+import (
+	"context"
+
+	"github.com/dylanmazurek/go-findmy/internal/publisher/models"
+	"github.com/dylanmazurek/go-findmy/pkg/geofence"
+	shared "github.com/dylanmazurek/go-findmy/pkg/shared/models"
+)
+
+// Publisher is a sink for device inventory and decrypted location reports
+type Publisher interface {
+	PublishDevice(ctx context.Context, device models.Device) error
+	PublishReport(ctx context.Context, report shared.LocationReport) error
+	Close(ctx context.Context) error
+}
+
+// GeofencePublisher is implemented by publishers that can also forward geofence events
+type GeofencePublisher interface {
+	PublishGeofenceEvent(ctx context.Context, event geofence.Event) error
+}
@@ -0,0 +1,90 @@
+package jsonl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/dylanmazurek/go-findmy/internal/publisher/models"
+	shared "github.com/dylanmazurek/go-findmy/pkg/shared/models"
+)
+
+// Publisher writes every device and report as a JSON line to a writer
+type Publisher struct {
+	mu     sync.Mutex
+	writer io.Writer
+	closer io.Closer
+}
+
+func NewPublisher(w io.Writer) *Publisher {
+	newPublisher := &Publisher{
+		writer: w,
+	}
+
+	return newPublisher
+}
+
+func NewStdoutPublisher() *Publisher {
+	return NewPublisher(os.Stdout)
+}
+
+func NewFilePublisher(path string) (*Publisher, error) {
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create publish directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open publish file: %w", err)
+	}
+
+	newPublisher := &Publisher{
+		writer: file,
+		closer: file,
+	}
+
+	return newPublisher, nil
+}
+
+func (p *Publisher) PublishDevice(ctx context.Context, device models.Device) error {
+	return p.write(models.NewDeviceMessage(device))
+}
+
+func (p *Publisher) PublishReport(ctx context.Context, report shared.LocationReport) error {
+	return p.write(models.NewReportMessage(report))
+}
+
+func (p *Publisher) write(message models.Message) error {
+	messageJson, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	messageJson = append(messageJson, '\n')
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	_, err = p.writer.Write(messageJson)
+
+	return err
+}
+
+func (p *Publisher) Close(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closer == nil {
+		return nil
+	}
+
+	err := p.closer.Close()
+	p.closer = nil
+
+	return err
+}
@@ -1,9 +1,88 @@
 package models
 
+import (
+	_ "embed"
+	"time"
+
+	shared "github.com/dylanmazurek/go-findmy/pkg/shared/models"
+)
+
+// ATTRIBUTES_SCHEMA_VERSION is bumped whenever a field is removed or changes
+// meaning, adding fields keeps the version
+const ATTRIBUTES_SCHEMA_VERSION = 1
+
+// AttributesSchema is the JSON schema of the tracker attributes payload
+//
+//go:embed schema/attributes.v1.json
+var AttributesSchema []byte
+
+// Report is published as the tracker attributes, see AttributesSchema
 type Report struct {
-	UniqueId  string  `json:"unique_id"`
-	Latitude  float64 `json:"latitude"`
-	Longitude float64 `json:"longitude"`
-	Altitude  float64 `json:"altitude,omitempty"`
-	Accuracy  float64 `json:"gps_accuracy,omitempty"`
+	SchemaVersion int       `json:"schema_version"`
+	UniqueId      string    `json:"unique_id"`
+	SourceType    string    `json:"source_type"`
+	Latitude      *float64  `json:"latitude,omitempty"`
+	Longitude     *float64  `json:"longitude,omitempty"`
+	Altitude      float64   `json:"altitude,omitempty"`
+	Accuracy      float64   `json:"gps_accuracy,omitempty"`
+	LastSeen      time.Time `json:"last_seen"`
+	Age           int64     `json:"age"`
+	ReportType    string    `json:"report_type"`
+	Source        string    `json:"source"`
+	IsOwnReport   bool      `json:"is_own_report,omitempty"`
+	SemanticName  string    `json:"semantic_name,omitempty"`
+	ReportCount   int       `json:"report_count,omitempty"`
+	IsOwner       *bool     `json:"is_owner,omitempty"`
+	Owner         string    `json:"owner,omitempty"`
+	SharedWith    []string  `json:"shared_with,omitempty"`
+}
+
+// Access is who the device is shared with, from its access information
+type Access struct {
+	Owner      string
+	IsOwner    bool
+	SharedWith []string
+}
+
+// Info is published retained on the info topic of each device
+type Info struct {
+	DeviceType string `json:"device_type"`
+}
+
+// NewReport builds the attributes for a report published at now, with the
+// device access when known
+func NewReport(report shared.LocationReport, access *Access, now time.Time) Report {
+	newReport := Report{
+		SchemaVersion: ATTRIBUTES_SCHEMA_VERSION,
+		SourceType:    "gps",
+		Altitude:      report.Altitude,
+		Accuracy:      report.Accuracy,
+		LastSeen:      report.ReportTime,
+		Age:           int64(max(now.Sub(report.ReportTime), 0) / time.Second),
+		ReportType:    report.ReportType.String(),
+		Source:        report.Status.String(),
+		IsOwnReport:   report.IsOwnReport,
+		ReportCount:   report.ReportCount,
+	}
+
+	if report.HasLocation() {
+		newReport.Latitude = &report.Latitude
+		newReport.Longitude = &report.Longitude
+	}
+
+	if report.UniqueId != nil {
+		newReport.UniqueId = *report.UniqueId
+	}
+
+	if report.SemanticName != nil {
+		newReport.SemanticName = *report.SemanticName
+	}
+
+	if access != nil {
+		newReport.IsOwner = &access.IsOwner
+		newReport.Owner = access.Owner
+		newReport.SharedWith = access.SharedWith
+	}
+
+	return newReport
 }
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/dylanmazurek/go-findmy/internal/publisher/constants"
+)
+
+// Availability is a Home Assistant availability topic, using the default
+// online and offline payloads
+type Availability struct {
+	Topic string `json:"topic"`
+}
+
+// GetAvailabilityTopic is online while the device has reported recently
+func GetAvailabilityTopic(uniqueId string) string {
+	topic := fmt.Sprintf("findmy2mqtt/%s/availability", uniqueId)
+
+	return topic
+}
+
+// NewBridgeAvailability follows the service itself, which is marked offline
+// by its last will
+func NewBridgeAvailability() []Availability {
+	return []Availability{
+		{Topic: constants.TOPIC_AVAILABILITY},
+	}
+}
+
+// NewDeviceAvailability follows both the service and the device's own
+// availability, and is used with an availability mode of all
+func NewDeviceAvailability(uniqueId string) []Availability {
+	return append(NewBridgeAvailability(), Availability{Topic: GetAvailabilityTopic(uniqueId)})
+}
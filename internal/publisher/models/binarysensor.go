@@ -0,0 +1,53 @@
+package models
+
+import "fmt"
+
+type BinarySensor struct {
+	UniqueId     string         `json:"unique_id"`
+	Name         string         `json:"name"`
+	DeviceClass  string         `json:"device_class"`
+	StateTopic   string         `json:"state_topic"`
+	PayloadOn    string         `json:"payload_on"`
+	PayloadOff   string         `json:"payload_off"`
+	DeviceInfo   DeviceInfo     `json:"device"`
+	Availability []Availability `json:"availability,omitempty"`
+
+	deviceUniqueId string
+	objectId       string
+}
+
+func NewGeofenceSensor(device Device, geofenceId string, geofenceName string) BinarySensor {
+	newSensor := BinarySensor{
+		UniqueId:     fmt.Sprintf("%s_geofence_%s", device.UniqueId, geofenceId),
+		Name:         geofenceName,
+		DeviceClass:  "presence",
+		StateTopic:   GetGeofenceStateTopic(device.UniqueId, geofenceId),
+		PayloadOn:    "ON",
+		PayloadOff:   "OFF",
+		DeviceInfo:   device.DeviceInfo,
+		Availability: NewBridgeAvailability(),
+
+		deviceUniqueId: device.UniqueId,
+		objectId:       fmt.Sprintf("geofence_%s", geofenceId),
+	}
+
+	return newSensor
+}
+
+func (b *BinarySensor) GetConfigTopic() string {
+	topic := fmt.Sprintf("homeassistant/binary_sensor/%s/%s/config", b.deviceUniqueId, b.objectId)
+
+	return topic
+}
+
+func GetGeofenceEventTopic(uniqueId string, geofenceId string) string {
+	topic := fmt.Sprintf("findmy2mqtt/%s/geofence/%s", uniqueId, geofenceId)
+
+	return topic
+}
+
+func GetGeofenceStateTopic(uniqueId string, geofenceId string) string {
+	topic := fmt.Sprintf("findmy2mqtt/%s/geofence/%s/state", uniqueId, geofenceId)
+
+	return topic
+}
@@ -0,0 +1,37 @@
+package models
+
+import "github.com/dylanmazurek/go-findmy/internal/publisher/constants"
+
+// NewBridgeDeviceInfo describes go-findmy itself, so trackers can be linked
+// to it with via_device
+func NewBridgeDeviceInfo(version string, configurationUrl string) DeviceInfo {
+	newDeviceInfo := DeviceInfo{
+		Identifiers:      []string{constants.BRIDGE_ID},
+		Name:             constants.BRIDGE_NAME,
+		Model:            "Find My Device bridge",
+		Manufacturer:     constants.BRIDGE_NAME,
+		SwVersion:        version,
+		ConfigurationUrl: configurationUrl,
+	}
+
+	return newDeviceInfo
+}
+
+// NewBridgeSensor is a connectivity sensor following the bridge availability
+// topic, which also registers the bridge device in Home Assistant
+func NewBridgeSensor(deviceInfo DeviceInfo) BinarySensor {
+	newSensor := BinarySensor{
+		UniqueId:    constants.BRIDGE_ID + "_connectivity",
+		Name:        "Connectivity",
+		DeviceClass: "connectivity",
+		StateTopic:  constants.TOPIC_AVAILABILITY,
+		PayloadOn:   constants.PAYLOAD_ONLINE,
+		PayloadOff:  constants.PAYLOAD_OFFLINE,
+		DeviceInfo:  deviceInfo,
+
+		deviceUniqueId: constants.BRIDGE_ID,
+		objectId:       "connectivity",
+	}
+
+	return newSensor
+}
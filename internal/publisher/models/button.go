@@ -0,0 +1,52 @@
+package models
+
+import "fmt"
+
+type Button struct {
+	UniqueId     string         `json:"unique_id"`
+	Name         string         `json:"name"`
+	Icon         string         `json:"icon,omitempty"`
+	CommandTopic string         `json:"command_topic"`
+	PayloadPress Command        `json:"payload_press"`
+	DeviceInfo   DeviceInfo     `json:"device"`
+	Availability []Availability `json:"availability,omitempty"`
+
+	deviceUniqueId string
+}
+
+var buttonCommands = []struct {
+	command Command
+	name    string
+	icon    string
+}{
+	{CommandLocate, "Locate", "mdi:crosshairs-gps"},
+	{CommandRing, "Ring", "mdi:bell-ring"},
+	{CommandStopRing, "Stop ring", "mdi:bell-off"},
+}
+
+func NewButtons(device Device) []Button {
+	var buttons []Button
+	for _, buttonCommand := range buttonCommands {
+		newButton := Button{
+			UniqueId:     fmt.Sprintf("%s_%s", device.UniqueId, buttonCommand.command),
+			Name:         buttonCommand.name,
+			Icon:         buttonCommand.icon,
+			CommandTopic: device.GetCommandTopic(),
+			PayloadPress: buttonCommand.command,
+			DeviceInfo:   device.DeviceInfo,
+			Availability: NewBridgeAvailability(),
+
+			deviceUniqueId: device.UniqueId,
+		}
+
+		buttons = append(buttons, newButton)
+	}
+
+	return buttons
+}
+
+func (b *Button) GetConfigTopic() string {
+	topic := fmt.Sprintf("homeassistant/button/%s/%s/config", b.deviceUniqueId, b.PayloadPress)
+
+	return topic
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Command string
+
+const (
+	CommandLocate   Command = "locate"
+	CommandRing     Command = "ring"
+	CommandStopRing Command = "stop_ring"
+	CommandRefresh  Command = "refresh"
+)
+
+func ParseCommand(payload string) (Command, error) {
+	command := Command(strings.ToLower(strings.TrimSpace(payload)))
+
+	switch command {
+	case CommandLocate, CommandRing, CommandStopRing, CommandRefresh:
+		return command, nil
+	}
+
+	return "", fmt.Errorf("unknown command: %s", payload)
+}
@@ -3,13 +3,30 @@ package models
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
+
+	"github.com/dylanmazurek/go-findmy/internal/publisher/constants"
+	shared "github.com/dylanmazurek/go-findmy/pkg/shared/models"
 )
 
+// PAYLOAD_RESET clears the location name so the state follows the
+// coordinates again
+const PAYLOAD_RESET = "None"
+
 type Device struct {
-	UniqueId    string     `json:"unique_id"`
-	Name        string     `json:"name"`
-	DeviceClass string     `json:"device_class"`
-	DeviceInfo  DeviceInfo `json:"device"`
+	UniqueId         string         `json:"unique_id"`
+	Name             string         `json:"name"`
+	DeviceClass      string         `json:"device_class"`
+	EntityPicture    string         `json:"entity_picture,omitempty"`
+	DeviceInfo       DeviceInfo     `json:"device"`
+	Availability     []Availability `json:"availability,omitempty"`
+	AvailabilityMode string         `json:"availability_mode,omitempty"`
+
+	// DeviceType is published on the info topic rather than in discovery
+	DeviceType string `json:"-"`
+
+	// Access is added to the attributes of every report when known
+	Access *Access `json:"-"`
 }
 
 func (d Device) MarshalJSON() ([]byte, error) {
@@ -17,9 +34,13 @@ func (d Device) MarshalJSON() ([]byte, error) {
 	alias := &struct {
 		Alias
 		AttributesTopic string `json:"json_attributes_topic"`
+		StateTopic      string `json:"state_topic"`
+		PayloadReset    string `json:"payload_reset"`
 	}{
 		Alias:           (Alias)(d),
 		AttributesTopic: d.GetAttributesTopic(),
+		StateTopic:      d.GetStateTopic(),
+		PayloadReset:    PAYLOAD_RESET,
 	}
 
 	return json.Marshal(alias)
@@ -35,7 +56,10 @@ func NewDevice(name string, uniqueId string, model string, manufacturer string)
 			Name:         name,
 			Model:        model,
 			Manufacturer: manufacturer,
+			ViaDevice:    constants.BRIDGE_ID,
 		},
+		Availability:     NewDeviceAvailability(uniqueId),
+		AvailabilityMode: "all",
 	}
 
 	return newDevice
@@ -52,3 +76,41 @@ func (d *Device) GetAttributesTopic() string {
 
 	return topic
 }
+
+// GetStateTopic carries the location name of semantic reports, which Home
+// Assistant shows in place of a zone from the coordinates
+func (d *Device) GetStateTopic() string {
+	return GetStateTopic(d.UniqueId)
+}
+
+func GetStateTopic(uniqueId string) string {
+	topic := fmt.Sprintf("findmy2mqtt/%s/state", uniqueId)
+
+	return topic
+}
+
+func (d *Device) GetCommandTopic() string {
+	topic := fmt.Sprintf("findmy2mqtt/%s/command", d.UniqueId)
+
+	return topic
+}
+
+func (d *Device) GetInfoTopic() string {
+	topic := fmt.Sprintf("findmy2mqtt/%s/info", d.UniqueId)
+
+	return topic
+}
+
+// GetLocationName returns the state for a report, the semantic name with
+// Home mapped to the home zone, or PAYLOAD_RESET for location reports
+func GetLocationName(report shared.LocationReport) string {
+	if report.ReportType != shared.ReportTypeSemantic || report.SemanticName == nil {
+		return PAYLOAD_RESET
+	}
+
+	if strings.EqualFold(*report.SemanticName, "home") {
+		return "home"
+	}
+
+	return *report.SemanticName
+}
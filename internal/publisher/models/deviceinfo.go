@@ -1,8 +1,11 @@
 package models
 
 type DeviceInfo struct {
-	Identifiers  []string `json:"identifiers"`
-	Name         string   `json:"name"`
-	Model        string   `json:"model"`
-	Manufacturer string   `json:"manufacturer"`
+	Identifiers      []string `json:"identifiers"`
+	Name             string   `json:"name"`
+	Model            string   `json:"model"`
+	Manufacturer     string   `json:"manufacturer"`
+	SwVersion        string   `json:"sw_version,omitempty"`
+	ViaDevice        string   `json:"via_device,omitempty"`
+	ConfigurationUrl string   `json:"configuration_url,omitempty"`
 }
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"time"
+
+	shared "github.com/dylanmazurek/go-findmy/pkg/shared/models"
+)
+
+type MessageType string
+
+const (
+	MessageTypeDevice MessageType = "device"
+	MessageTypeReport MessageType = "report"
+)
+
+// Message is the envelope written by the non-MQTT publishers
+type Message struct {
+	Type   MessageType            `json:"type"`
+	Time   time.Time              `json:"time"`
+	Device *DeviceDetails         `json:"device,omitempty"`
+	Report *shared.LocationReport `json:"report,omitempty"`
+}
+
+// DeviceDetails describes a device for the non-MQTT publishers, without the
+// Home Assistant discovery fields
+type DeviceDetails struct {
+	UniqueId     string   `json:"unique_id"`
+	Name         string   `json:"name"`
+	Model        string   `json:"model,omitempty"`
+	Manufacturer string   `json:"manufacturer,omitempty"`
+	DeviceType   string   `json:"device_type,omitempty"`
+	Picture      string   `json:"picture,omitempty"`
+	Owner        string   `json:"owner,omitempty"`
+	IsOwner      *bool    `json:"is_owner,omitempty"`
+	SharedWith   []string `json:"shared_with,omitempty"`
+}
+
+func NewDeviceDetails(device Device) DeviceDetails {
+	newDeviceDetails := DeviceDetails{
+		UniqueId:     device.UniqueId,
+		Name:         device.DeviceInfo.Name,
+		Model:        device.DeviceInfo.Model,
+		Manufacturer: device.DeviceInfo.Manufacturer,
+		DeviceType:   device.DeviceType,
+		Picture:      device.EntityPicture,
+	}
+
+	if device.Access != nil {
+		newDeviceDetails.Owner = device.Access.Owner
+		newDeviceDetails.IsOwner = &device.Access.IsOwner
+		newDeviceDetails.SharedWith = device.Access.SharedWith
+	}
+
+	return newDeviceDetails
+}
+
+func NewDeviceMessage(device Device) Message {
+	deviceDetails := NewDeviceDetails(device)
+
+	newMessage := Message{
+		Type:   MessageTypeDevice,
+		Time:   time.Now().UTC(),
+		Device: &deviceDetails,
+	}
+
+	return newMessage
+}
+
+func NewReportMessage(report shared.LocationReport) Message {
+	newMessage := Message{
+		Type:   MessageTypeReport,
+		Time:   time.Now().UTC(),
+		Report: &report,
+	}
+
+	return newMessage
+}
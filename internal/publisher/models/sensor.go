@@ -0,0 +1,100 @@
+package models
+
+import "fmt"
+
+type Sensor struct {
+	UniqueId          string         `json:"unique_id"`
+	Name              string         `json:"name"`
+	Icon              string         `json:"icon,omitempty"`
+	DeviceClass       string         `json:"device_class,omitempty"`
+	StateClass        string         `json:"state_class,omitempty"`
+	UnitOfMeasurement string         `json:"unit_of_measurement,omitempty"`
+	EntityCategory    string         `json:"entity_category,omitempty"`
+	Options           []string       `json:"options,omitempty"`
+	StateTopic        string         `json:"state_topic"`
+	ValueTemplate     string         `json:"value_template"`
+	DeviceInfo        DeviceInfo     `json:"device"`
+	Availability      []Availability `json:"availability,omitempty"`
+	AvailabilityMode  string         `json:"availability_mode,omitempty"`
+
+	deviceUniqueId string
+	objectId       string
+}
+
+// ReportSources are the values of the source attribute
+var ReportSources = []string{"semantic", "last_known", "crowdsourced", "aggregated"}
+
+// NewSensors returns the sensors published alongside each device tracker,
+// read from its attributes and info topics
+func NewSensors(device Device) []Sensor {
+	attributesTopic := device.GetAttributesTopic()
+
+	sensors := []Sensor{
+		{
+			Name:          "Last seen",
+			DeviceClass:   "timestamp",
+			StateTopic:    attributesTopic,
+			ValueTemplate: "{{ value_json.last_seen }}",
+			objectId:      "last_seen",
+		},
+		{
+			Name:              "Accuracy",
+			Icon:              "mdi:map-marker-radius",
+			DeviceClass:       "distance",
+			StateClass:        "measurement",
+			UnitOfMeasurement: "m",
+			EntityCategory:    "diagnostic",
+			StateTopic:        attributesTopic,
+			ValueTemplate:     "{{ value_json.gps_accuracy | default(0) | round(0) }}",
+			objectId:          "accuracy",
+		},
+		{
+			Name:           "Source",
+			Icon:           "mdi:access-point-network",
+			DeviceClass:    "enum",
+			EntityCategory: "diagnostic",
+			Options:        ReportSources,
+			StateTopic:     attributesTopic,
+			ValueTemplate:  "{{ value_json.source }}",
+			objectId:       "source",
+		},
+		{
+			Name:          "Semantic location",
+			Icon:          "mdi:home-map-marker",
+			StateTopic:    attributesTopic,
+			ValueTemplate: "{{ value_json.semantic_name | default('') }}",
+			objectId:      "semantic_location",
+		},
+		{
+			Name:           "Device type",
+			Icon:           "mdi:tag",
+			EntityCategory: "diagnostic",
+			StateTopic:     device.GetInfoTopic(),
+			ValueTemplate:  "{{ value_json.device_type }}",
+			objectId:       "device_type",
+		},
+	}
+
+	for i := range sensors {
+		sensors[i].UniqueId = fmt.Sprintf("%s_%s", device.UniqueId, sensors[i].objectId)
+		sensors[i].DeviceInfo = device.DeviceInfo
+		sensors[i].deviceUniqueId = device.UniqueId
+
+		// the device type doesn't go stale, so it only follows the bridge
+		if sensors[i].StateTopic == device.GetInfoTopic() {
+			sensors[i].Availability = NewBridgeAvailability()
+			continue
+		}
+
+		sensors[i].Availability = device.Availability
+		sensors[i].AvailabilityMode = device.AvailabilityMode
+	}
+
+	return sensors
+}
+
+func (s *Sensor) GetConfigTopic() string {
+	topic := fmt.Sprintf("homeassistant/sensor/%s/%s/config", s.deviceUniqueId, s.objectId)
+
+	return topic
+}
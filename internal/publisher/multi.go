@@ -0,0 +1,69 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dylanmazurek/go-findmy/internal/publisher/models"
+	"github.com/dylanmazurek/go-findmy/pkg/geofence"
+	shared "github.com/dylanmazurek/go-findmy/pkg/shared/models"
+)
+
+// MultiPublisher fans every call out to all of its publishers. A failing
+// publisher does not stop the others, errors are joined and returned.
+type MultiPublisher struct {
+	publishers []Publisher
+}
+
+func NewMultiPublisher(publishers ...Publisher) *MultiPublisher {
+	newMultiPublisher := &MultiPublisher{
+		publishers: publishers,
+	}
+
+	return newMultiPublisher
+}
+
+func (m *MultiPublisher) Len() int {
+	return len(m.publishers)
+}
+
+func (m *MultiPublisher) PublishDevice(ctx context.Context, device models.Device) error {
+	var errs []error
+	for _, p := range m.publishers {
+		errs = append(errs, p.PublishDevice(ctx, device))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (m *MultiPublisher) PublishReport(ctx context.Context, report shared.LocationReport) error {
+	var errs []error
+	for _, p := range m.publishers {
+		errs = append(errs, p.PublishReport(ctx, report))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (m *MultiPublisher) PublishGeofenceEvent(ctx context.Context, event geofence.Event) error {
+	var errs []error
+	for _, p := range m.publishers {
+		geofencePublisher, ok := p.(GeofencePublisher)
+		if !ok {
+			continue
+		}
+
+		errs = append(errs, geofencePublisher.PublishGeofenceEvent(ctx, event))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (m *MultiPublisher) Close(ctx context.Context) error {
+	var errs []error
+	for _, p := range m.publishers {
+		errs = append(errs, p.Close(ctx))
+	}
+
+	return errors.Join(errs...)
+}
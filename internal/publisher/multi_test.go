@@ -0,0 +1,84 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dylanmazurek/go-findmy/internal/publisher/models"
+	"github.com/dylanmazurek/go-findmy/pkg/geofence"
+	shared "github.com/dylanmazurek/go-findmy/pkg/shared/models"
+)
+
+type recordingPublisher struct {
+	devices []models.Device
+	reports []shared.LocationReport
+	closed  bool
+	err     error
+}
+
+func (r *recordingPublisher) PublishDevice(ctx context.Context, device models.Device) error {
+	r.devices = append(r.devices, device)
+	return r.err
+}
+
+func (r *recordingPublisher) PublishReport(ctx context.Context, report shared.LocationReport) error {
+	r.reports = append(r.reports, report)
+	return r.err
+}
+
+func (r *recordingPublisher) Close(ctx context.Context) error {
+	r.closed = true
+	return r.err
+}
+
+type geofenceRecordingPublisher struct {
+	recordingPublisher
+	events []geofence.Event
+}
+
+func (g *geofenceRecordingPublisher) PublishGeofenceEvent(ctx context.Context, event geofence.Event) error {
+	g.events = append(g.events, event)
+	return nil
+}
+
+func TestMultiPublisherFansOut(t *testing.T) {
+	ctx := context.Background()
+
+	failing := &recordingPublisher{err: errors.New("sink down")}
+	healthy := &geofenceRecordingPublisher{}
+
+	multi := NewMultiPublisher(failing, healthy)
+
+	err := multi.PublishReport(ctx, shared.LocationReport{})
+	if !errors.Is(err, failing.err) {
+		t.Errorf("PublishReport: expected error from failing sink, got %v", err)
+	}
+
+	if len(failing.reports) != 1 || len(healthy.reports) != 1 {
+		t.Errorf("PublishReport: expected every sink to receive the report")
+	}
+
+	err = multi.PublishDevice(ctx, models.NewDevice("Keys", "tracker-a", "Tag", "Acme"))
+	if !errors.Is(err, failing.err) {
+		t.Errorf("PublishDevice: expected error from failing sink, got %v", err)
+	}
+
+	if len(healthy.devices) != 1 {
+		t.Errorf("PublishDevice: expected healthy sink to receive the device")
+	}
+
+	err = multi.PublishGeofenceEvent(ctx, geofence.Event{Type: geofence.EventEnter})
+	if err != nil {
+		t.Errorf("PublishGeofenceEvent: expected sinks without geofence support to be skipped, got %v", err)
+	}
+
+	if len(healthy.events) != 1 {
+		t.Errorf("PublishGeofenceEvent: expected geofence capable sink to receive the event")
+	}
+
+	multi.Close(ctx)
+	if !failing.closed || !healthy.closed {
+		t.Errorf("Close: expected every sink to be closed")
+	}
+}
@@ -0,0 +1,32 @@
+package publisher
+
+import "time"
+
+type Options struct {
+	staleAfter       time.Duration
+	configurationUrl string
+}
+
+func DefaultOptions() Options {
+	defaultOptions := Options{}
+
+	return defaultOptions
+}
+
+type Option func(*Options)
+
+// WithStaleAfter marks devices unavailable once they have not reported for
+// the given duration. Devices are always available when it is zero
+func WithStaleAfter(staleAfter time.Duration) Option {
+	return func(o *Options) {
+		o.staleAfter = staleAfter
+	}
+}
+
+// WithConfigurationUrl links the go-findmy device in Home Assistant to the
+// given url, such as the API address
+func WithConfigurationUrl(configurationUrl string) Option {
+	return func(o *Options) {
+		o.configurationUrl = configurationUrl
+	}
+}
@@ -0,0 +1,100 @@
+package owntracks
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/dylanmazurek/go-findmy/internal/publisher/models"
+	shared "github.com/dylanmazurek/go-findmy/pkg/shared/models"
+)
+
+const (
+	TYPE_LOCATION = "location"
+	TYPE_CARD     = "card"
+)
+
+var invalidTopicChars = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+// Location is an OwnTracks `_type: location` message
+type Location struct {
+	Type      string   `json:"_type"`
+	TrackerId string   `json:"tid"`
+	Latitude  float64  `json:"lat"`
+	Longitude float64  `json:"lon"`
+	Altitude  int      `json:"alt"`
+	Accuracy  int      `json:"acc"`
+	Timestamp int64    `json:"tst"`
+	CreatedAt int64    `json:"created_at"`
+	InRegions []string `json:"inregions,omitempty"`
+	Topic     string   `json:"topic,omitempty"`
+}
+
+// Card is an OwnTracks `_type: card` message, used to give a tracker a name
+type Card struct {
+	Type      string `json:"_type"`
+	TrackerId string `json:"tid"`
+	Name      string `json:"name"`
+	Topic     string `json:"topic,omitempty"`
+}
+
+func NewLocation(report shared.LocationReport, topic string) Location {
+	newLocation := Location{
+		Type:      TYPE_LOCATION,
+		TrackerId: TrackerId(*report.UniqueId),
+		Latitude:  report.Latitude,
+		Longitude: report.Longitude,
+		Altitude:  int(report.Altitude),
+		Accuracy:  int(report.Accuracy),
+		Timestamp: report.ReportTime.Unix(),
+		CreatedAt: time.Now().Unix(),
+		Topic:     topic,
+	}
+
+	if report.SemanticName != nil {
+		newLocation.InRegions = []string{*report.SemanticName}
+	}
+
+	return newLocation
+}
+
+func NewCard(device models.Device, topic string) Card {
+	newCard := Card{
+		Type:      TYPE_CARD,
+		TrackerId: TrackerId(device.UniqueId),
+		Name:      device.DeviceInfo.Name,
+		Topic:     topic,
+	}
+
+	return newCard
+}
+
+// TrackerId returns the two character OwnTracks tracker id for a unique id
+func TrackerId(uniqueId string) string {
+	trackerId := strings.ToUpper(DeviceName(uniqueId))
+	if len(trackerId) > 2 {
+		trackerId = trackerId[len(trackerId)-2:]
+	}
+
+	return trackerId
+}
+
+// DeviceName returns the unique id made safe for use as an OwnTracks topic level
+func DeviceName(uniqueId string) string {
+	deviceName := invalidTopicChars.ReplaceAllString(strings.ToLower(uniqueId), "")
+
+	return deviceName
+}
+
+func locationTopic(user string, uniqueId string) string {
+	topic := fmt.Sprintf("owntracks/%s/%s", user, DeviceName(uniqueId))
+
+	return topic
+}
+
+func cardTopic(user string, uniqueId string) string {
+	topic := fmt.Sprintf("%s/info", locationTopic(user, uniqueId))
+
+	return topic
+}
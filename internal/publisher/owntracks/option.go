@@ -0,0 +1,39 @@
+package owntracks
+
+import (
+	"time"
+)
+
+type Options struct {
+	user     string
+	username *string
+	password *string
+	timeout  time.Duration
+}
+
+func DefaultOptions() Options {
+	defaultOptions := Options{
+		user:    "findmy",
+		timeout: 10 * time.Second,
+	}
+
+	return defaultOptions
+}
+
+type Option func(*Options)
+
+// WithUser sets the OwnTracks user, the `<user>` level of `owntracks/<user>/<device>`
+func WithUser(user string) Option {
+	return func(o *Options) {
+		o.user = user
+	}
+}
+
+// WithCredentials authenticates against the MQTT broker or, in HTTP mode,
+// the recorder with basic auth
+func WithCredentials(username string, password string) Option {
+	return func(o *Options) {
+		o.username = &username
+		o.password = &password
+	}
+}
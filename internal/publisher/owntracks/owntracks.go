@@ -0,0 +1,131 @@
+package owntracks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/dylanmazurek/go-findmy/internal/publisher/models"
+	shared "github.com/dylanmazurek/go-findmy/pkg/shared/models"
+	"github.com/eclipse/paho.golang/autopaho"
+	"github.com/eclipse/paho.golang/paho"
+)
+
+// sender delivers an OwnTracks message to a topic, either over MQTT or the
+// OwnTracks HTTP mode
+type sender interface {
+	send(ctx context.Context, topic string, uniqueId string, payload []byte, retain bool) error
+	close(ctx context.Context) error
+}
+
+// Publisher converts devices and reports into OwnTracks card and location
+// messages
+type Publisher struct {
+	user   string
+	sender sender
+}
+
+// NewMqttPublisher publishes OwnTracks messages to `owntracks/<user>/<device>`
+// on the given MQTT broker
+func NewMqttPublisher(ctx context.Context, mqttUrl string, opts ...Option) (*Publisher, error) {
+	publisherOptions := DefaultOptions()
+	for _, opt := range opts {
+		opt(&publisherOptions)
+	}
+
+	u, err := url.Parse(mqttUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	cliCfg := autopaho.ClientConfig{
+		ServerUrls:                    []*url.URL{u},
+		KeepAlive:                     20,
+		CleanStartOnInitialConnection: false,
+		SessionExpiryInterval:         60,
+		ClientConfig: paho.ClientConfig{
+			ClientID: fmt.Sprintf("findmy2owntracks-%s", publisherOptions.user),
+		},
+	}
+
+	if publisherOptions.username != nil {
+		cliCfg.ConnectUsername = *publisherOptions.username
+		cliCfg.ConnectPassword = []byte(*publisherOptions.password)
+	}
+
+	c, err := autopaho.NewConnection(ctx, cliCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.AwaitConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	newPublisher := &Publisher{
+		user:   publisherOptions.user,
+		sender: &mqttSender{internalClient: c},
+	}
+
+	return newPublisher, nil
+}
+
+// NewHttpPublisher POSTs OwnTracks messages to a recorder or Home Assistant
+// OwnTracks webhook using the OwnTracks HTTP mode
+func NewHttpPublisher(ctx context.Context, endpoint string, opts ...Option) (*Publisher, error) {
+	publisherOptions := DefaultOptions()
+	for _, opt := range opts {
+		opt(&publisherOptions)
+	}
+
+	newSender, err := newHttpSender(endpoint, publisherOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	newPublisher := &Publisher{
+		user:   publisherOptions.user,
+		sender: newSender,
+	}
+
+	return newPublisher, nil
+}
+
+func (p *Publisher) PublishDevice(ctx context.Context, device models.Device) error {
+	topic := cardTopic(p.user, device.UniqueId)
+	card := NewCard(device, locationTopic(p.user, device.UniqueId))
+
+	cardJson, err := json.Marshal(card)
+	if err != nil {
+		return err
+	}
+
+	return p.sender.send(ctx, topic, device.UniqueId, cardJson, true)
+}
+
+func (p *Publisher) PublishReport(ctx context.Context, report shared.LocationReport) error {
+	if report.UniqueId == nil {
+		return fmt.Errorf("report has no unique id")
+	}
+
+	// locations need coordinates, unknown semantic locations are skipped
+	if !report.HasLocation() {
+		return nil
+	}
+
+	topic := locationTopic(p.user, *report.UniqueId)
+	location := NewLocation(report, topic)
+
+	locationJson, err := json.Marshal(location)
+	if err != nil {
+		return err
+	}
+
+	return p.sender.send(ctx, topic, *report.UniqueId, locationJson, true)
+}
+
+func (p *Publisher) Close(ctx context.Context) error {
+	return p.sender.close(ctx)
+}
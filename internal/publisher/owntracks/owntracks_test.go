@@ -0,0 +1,72 @@
+package owntracks
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	shared "github.com/dylanmazurek/go-findmy/pkg/shared/models"
+)
+
+func TestHttpPublisherSendsLocation(t *testing.T) {
+	ctx := context.Background()
+
+	var received Location
+	var header http.Header
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer server.Close()
+
+	publisher, err := NewHttpPublisher(ctx, server.URL, WithUser("alice"), WithCredentials("alice", "hunter2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uniqueId := "6A1B-29CF"
+	semanticName := "Home"
+	reportTime := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	err = publisher.PublishReport(ctx, shared.LocationReport{
+		UniqueId:     &uniqueId,
+		ReportTime:   reportTime,
+		Latitude:     -37.81,
+		Longitude:    144.96,
+		Altitude:     31.6,
+		Accuracy:     12.4,
+		SemanticName: &semanticName,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if header.Get(HEADER_USER) != "alice" || header.Get(HEADER_DEVICE) != "6a1b-29cf" {
+		t.Fatalf("unexpected routing headers %v", header)
+	}
+
+	username, password, ok := (&http.Request{Header: header}).BasicAuth()
+	if !ok || username != "alice" || password != "hunter2" {
+		t.Fatal("expected basic auth credentials")
+	}
+
+	if received.Type != TYPE_LOCATION || received.TrackerId != "CF" {
+		t.Fatalf("unexpected message %+v", received)
+	}
+
+	if received.Timestamp != reportTime.Unix() || received.Accuracy != 12 || received.Altitude != 31 {
+		t.Fatalf("unexpected location fields %+v", received)
+	}
+
+	if received.Topic != "owntracks/alice/6a1b-29cf" {
+		t.Fatalf("unexpected topic %s", received.Topic)
+	}
+
+	if len(received.InRegions) != 1 || received.InRegions[0] != "Home" {
+		t.Fatalf("expected semantic name in regions, got %v", received.InRegions)
+	}
+}
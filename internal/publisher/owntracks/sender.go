@@ -0,0 +1,99 @@
+package owntracks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/eclipse/paho.golang/autopaho"
+	"github.com/eclipse/paho.golang/paho"
+)
+
+const (
+	HEADER_USER   = "X-Limit-U"
+	HEADER_DEVICE = "X-Limit-D"
+)
+
+type mqttSender struct {
+	internalClient *autopaho.ConnectionManager
+}
+
+func (m *mqttSender) send(ctx context.Context, topic string, uniqueId string, payload []byte, retain bool) error {
+	_, err := m.internalClient.Publish(ctx, &paho.Publish{
+		QoS:     1,
+		Topic:   topic,
+		Payload: payload,
+		Retain:  retain,
+	})
+
+	return err
+}
+
+func (m *mqttSender) close(ctx context.Context) error {
+	return m.internalClient.Disconnect(ctx)
+}
+
+type httpSender struct {
+	endpoint   string
+	user       string
+	username   *string
+	password   *string
+	httpClient *http.Client
+}
+
+func newHttpSender(endpoint string, opts Options) (*httpSender, error) {
+	_, err := url.ParseRequestURI(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid owntracks url: %w", err)
+	}
+
+	newSender := &httpSender{
+		endpoint: endpoint,
+		user:     opts.user,
+		username: opts.username,
+		password: opts.password,
+		httpClient: &http.Client{
+			Timeout: opts.timeout,
+		},
+	}
+
+	return newSender, nil
+}
+
+// send POSTs the payload with the user and device in the X-Limit headers,
+// which both the OwnTracks Recorder and Home Assistant use to route it
+func (h *httpSender) send(ctx context.Context, topic string, uniqueId string, payload []byte, retain bool) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HEADER_USER, h.user)
+	req.Header.Set(HEADER_DEVICE, DeviceName(uniqueId))
+
+	if h.username != nil {
+		req.SetBasicAuth(*h.username, *h.password)
+	}
+
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("owntracks endpoint responded with %s", resp.Status)
+	}
+
+	return nil
+}
+
+func (h *httpSender) close(ctx context.Context) error {
+	return nil
+}
@@ -5,29 +5,88 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/dylanmazurek/go-findmy/internal"
+	"github.com/dylanmazurek/go-findmy/internal/publisher/constants"
 	"github.com/dylanmazurek/go-findmy/internal/publisher/models"
+	"github.com/dylanmazurek/go-findmy/pkg/geofence"
+	shared "github.com/dylanmazurek/go-findmy/pkg/shared/models"
 	"github.com/eclipse/paho.golang/autopaho"
 	"github.com/eclipse/paho.golang/paho"
+	"github.com/rs/zerolog/log"
 )
 
+type CommandHandler func(ctx context.Context, uniqueId string, command models.Command) error
+
 type Client struct {
 	internalClient *autopaho.ConnectionManager
+	ctx            context.Context
+	staleAfter     time.Duration
+	bridgeInfo     models.DeviceInfo
+
+	mu             sync.RWMutex
+	commandCtx     context.Context
+	commandHandler CommandHandler
+
+	// devices and geofence sensors are kept so their discovery configs can be
+	// published again when Home Assistant restarts
+	stateMu         sync.Mutex
+	devices         map[string]models.Device
+	geofenceSensors map[string][]models.BinarySensor
+	lastSeen        map[string]time.Time
+	available       map[string]bool
+
+	stop chan struct{}
+	wg   sync.WaitGroup
 }
 
-func NewPublisher(ctx context.Context, mqttUrl string, username string, password string) (*Client, error) {
+func NewPublisher(ctx context.Context, mqttUrl string, username string, password string, opts ...Option) (*Client, error) {
+	clientOptions := DefaultOptions()
+	for _, opt := range opts {
+		opt(&clientOptions)
+	}
+
 	u, err := url.Parse(mqttUrl)
 	if err != nil {
 		return nil, err
 	}
 
+	newClient := &Client{
+		ctx:        ctx,
+		staleAfter: clientOptions.staleAfter,
+		bridgeInfo: models.NewBridgeDeviceInfo(internal.Version(), clientOptions.configurationUrl),
+
+		devices:         map[string]models.Device{},
+		geofenceSensors: map[string][]models.BinarySensor{},
+		lastSeen:        map[string]time.Time{},
+		available:       map[string]bool{},
+
+		stop: make(chan struct{}),
+	}
+
 	cliCfg := autopaho.ClientConfig{
 		ServerUrls:                    []*url.URL{u},
 		KeepAlive:                     20,
 		CleanStartOnInitialConnection: false,
 		SessionExpiryInterval:         60,
+		OnConnectionUp: func(cm *autopaho.ConnectionManager, connAck *paho.Connack) {
+			newClient.onConnectionUp(ctx, cm)
+		},
+		// the broker marks the service offline if it disconnects uncleanly
+		WillMessage: &paho.WillMessage{
+			Topic:   constants.TOPIC_AVAILABILITY,
+			Payload: []byte(constants.PAYLOAD_OFFLINE),
+			QoS:     1,
+			Retain:  true,
+		},
 		ClientConfig: paho.ClientConfig{
 			ClientID: fmt.Sprintf("findmy2mqtt-%s", username),
+			OnPublishReceived: []func(paho.PublishReceived) (bool, error){
+				newClient.onPublishReceived,
+			},
 		},
 	}
 
@@ -39,16 +98,237 @@ func NewPublisher(ctx context.Context, mqttUrl string, username string, password
 		return nil, err
 	}
 
+	newClient.internalClient = c
+
 	err = c.AwaitConnection(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	newClient := Client{
-		internalClient: c,
+	if newClient.staleAfter > 0 {
+		newClient.wg.Add(1)
+		go newClient.staleLoop(ctx)
 	}
 
-	return &newClient, nil
+	return newClient, nil
+}
+
+func (c *Client) SubscribeCommands(ctx context.Context, handler CommandHandler) error {
+	c.mu.Lock()
+	c.commandCtx = ctx
+	c.commandHandler = handler
+	c.mu.Unlock()
+
+	return c.subscribeCommands(ctx, c.internalClient)
+}
+
+func (c *Client) subscribeCommands(ctx context.Context, cm *autopaho.ConnectionManager) error {
+	return c.subscribe(ctx, cm, constants.TOPIC_COMMAND_SUBSCRIPTION)
+}
+
+func (c *Client) subscribe(ctx context.Context, cm *autopaho.ConnectionManager, topic string) error {
+	log := log.Ctx(ctx).With().Str("client", constants.SERVICE_NAME).Logger()
+
+	subscription := &paho.Subscribe{
+		Subscriptions: []paho.SubscribeOptions{
+			{Topic: topic, QoS: 1},
+		},
+	}
+
+	_, err := cm.Subscribe(ctx, subscription)
+	if err != nil {
+		log.Error().Err(err).Str("topic", topic).Msg("failed to subscribe")
+		return err
+	}
+
+	log.Debug().
+		Str("topic", topic).
+		Msg("subscribed")
+
+	return nil
+}
+
+// onConnectionUp marks the service online and restores the subscriptions
+// after every (re)connect
+func (c *Client) onConnectionUp(ctx context.Context, cm *autopaho.ConnectionManager) {
+	log := log.Ctx(ctx).With().Str("client", constants.SERVICE_NAME).Logger()
+
+	err := c.publishAvailability(ctx, cm, constants.PAYLOAD_ONLINE)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to publish availability")
+	}
+
+	err = c.publishBridge(ctx, cm)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to publish bridge discovery")
+	}
+
+	c.subscribe(ctx, cm, constants.TOPIC_HOMEASSISTANT_STATUS)
+
+	c.mu.RLock()
+	hasHandler := c.commandHandler != nil
+	c.mu.RUnlock()
+
+	if !hasHandler {
+		return
+	}
+
+	c.subscribeCommands(ctx, cm)
+}
+
+func (c *Client) onPublishReceived(pr paho.PublishReceived) (bool, error) {
+	if pr.Packet.Topic == constants.TOPIC_HOMEASSISTANT_STATUS {
+		c.onHomeAssistantStatus(string(pr.Packet.Payload))
+		return true, nil
+	}
+
+	c.mu.RLock()
+	ctx := c.commandCtx
+	handler := c.commandHandler
+	c.mu.RUnlock()
+
+	if handler == nil {
+		return false, nil
+	}
+
+	uniqueId, ok := parseCommandTopic(pr.Packet.Topic)
+	if !ok {
+		return false, nil
+	}
+
+	log := log.Ctx(ctx).With().
+		Str("client", constants.SERVICE_NAME).
+		Str("unique_id", uniqueId).
+		Logger()
+
+	command, err := models.ParseCommand(string(pr.Packet.Payload))
+	if err != nil {
+		log.Warn().Err(err).Msg("ignoring invalid command")
+		return true, nil
+	}
+
+	log.Info().Str("command", string(command)).Msg("received command")
+
+	go func() {
+		err := handler(ctx, uniqueId, command)
+		if err != nil {
+			log.Error().Err(err).Str("command", string(command)).Msg("failed to handle command")
+		}
+	}()
+
+	return true, nil
+}
+
+// onHomeAssistantStatus publishes every discovery config again when Home
+// Assistant sends its birth message, as it forgets non-retained state
+func (c *Client) onHomeAssistantStatus(status string) {
+	log := log.Ctx(c.ctx).With().Str("client", constants.SERVICE_NAME).Logger()
+
+	if status != constants.PAYLOAD_ONLINE {
+		return
+	}
+
+	log.Info().Msg("home assistant started, publishing discovery again")
+
+	go func() {
+		err := c.republish(c.ctx)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to publish discovery")
+		}
+	}()
+}
+
+func (c *Client) republish(ctx context.Context) error {
+	c.stateMu.Lock()
+	devices := make([]models.Device, 0, len(c.devices))
+	for _, device := range c.devices {
+		devices = append(devices, device)
+	}
+
+	var sensors []models.BinarySensor
+	for _, deviceSensors := range c.geofenceSensors {
+		sensors = append(sensors, deviceSensors...)
+	}
+	c.stateMu.Unlock()
+
+	err := c.publishBridge(ctx, c.internalClient)
+	if err != nil {
+		return err
+	}
+
+	for _, device := range devices {
+		_, err := c.publishDeviceConfigs(ctx, device)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, sensor := range sensors {
+		_, err := c.publishConfig(ctx, sensor.GetConfigTopic(), sensor)
+		if err != nil {
+			return err
+		}
+	}
+
+	return c.updateAvailability(ctx, true)
+}
+
+func parseCommandTopic(topic string) (string, bool) {
+	parts := strings.Split(topic, "/")
+	if len(parts) != 3 || parts[0] != "findmy2mqtt" || parts[2] != "command" || parts[1] == "" {
+		return "", false
+	}
+
+	return parts[1], true
+}
+
+func (c *Client) PublishDevice(ctx context.Context, device models.Device) error {
+	_, err := c.AddDevice(ctx, device)
+
+	return err
+}
+
+func (c *Client) PublishReport(ctx context.Context, report shared.LocationReport) error {
+	if report.UniqueId == nil {
+		return fmt.Errorf("report has no unique id")
+	}
+
+	c.SetLastSeen(*report.UniqueId, report.ReportTime)
+
+	c.stateMu.Lock()
+	device := c.devices[*report.UniqueId]
+	c.stateMu.Unlock()
+
+	pubReport := models.NewReport(report, device.Access, time.Now())
+
+	_, err := c.UpdateTracker(ctx, pubReport)
+	if err != nil {
+		return err
+	}
+
+	// the state is sent after the attributes, as Home Assistant clears the
+	// location name when it receives coordinates
+	err = c.UpdateState(ctx, *report.UniqueId, models.GetLocationName(report))
+	if err != nil {
+		return err
+	}
+
+	return c.updateAvailability(ctx, false)
+}
+
+// Close marks the service as offline before disconnecting
+func (c *Client) Close(ctx context.Context) error {
+	log := log.Ctx(ctx).With().Str("client", constants.SERVICE_NAME).Logger()
+
+	close(c.stop)
+	c.wg.Wait()
+
+	err := c.publishAvailability(ctx, c.internalClient, constants.PAYLOAD_OFFLINE)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to publish availability")
+	}
+
+	return c.internalClient.Disconnect(ctx)
 }
 
 func (c *Client) InitalizeDevices(ctx context.Context, devices []models.Device) ([]*paho.PublishResponse, error) {
@@ -67,16 +347,79 @@ func (c *Client) InitalizeDevices(ctx context.Context, devices []models.Device)
 }
 
 func (c *Client) AddDevice(ctx context.Context, device models.Device) (*paho.PublishResponse, error) {
-	deviceJson, err := json.MarshalIndent(device, "", " ")
+	c.stateMu.Lock()
+	c.devices[device.UniqueId] = device
+	c.stateMu.Unlock()
+
+	resp, err := c.publishDeviceConfigs(ctx, device)
+	if err != nil {
+		return resp, err
+	}
+
+	err = c.updateAvailability(ctx, true)
+
+	return resp, err
+}
+
+func (c *Client) publishDeviceConfigs(ctx context.Context, device models.Device) (*paho.PublishResponse, error) {
+	resp, err := c.publishConfig(ctx, device.GetConfigTopic(), device)
+	if err != nil {
+		return resp, err
+	}
+
+	for _, button := range models.NewButtons(device) {
+		_, err := c.publishConfig(ctx, button.GetConfigTopic(), button)
+		if err != nil {
+			return resp, err
+		}
+	}
+
+	for _, sensor := range models.NewSensors(device) {
+		_, err := c.publishConfig(ctx, sensor.GetConfigTopic(), sensor)
+		if err != nil {
+			return resp, err
+		}
+	}
+
+	if device.DeviceType != "" {
+		infoJson, err := json.Marshal(models.Info{DeviceType: device.DeviceType})
+		if err != nil {
+			return resp, err
+		}
+
+		err = c.publishRetained(ctx, c.internalClient, device.GetInfoTopic(), string(infoJson))
+		if err != nil {
+			return resp, err
+		}
+	}
+
+	return resp, err
+}
+
+// publishBridge publishes the go-findmy device that trackers are linked to
+// with via_device
+func (c *Client) publishBridge(ctx context.Context, cm *autopaho.ConnectionManager) error {
+	sensor := models.NewBridgeSensor(c.bridgeInfo)
+
+	configJson, err := json.MarshalIndent(sensor, "", " ")
+	if err != nil {
+		return err
+	}
+
+	return c.publishRetained(ctx, cm, sensor.GetConfigTopic(), string(configJson))
+}
+
+func (c *Client) publishConfig(ctx context.Context, topic string, config any) (*paho.PublishResponse, error) {
+	configJson, err := json.MarshalIndent(config, "", " ")
 	if err != nil {
 		return nil, err
 	}
 
 	payload := &paho.Publish{
 		QoS:     1,
-		Topic:   device.GetConfigTopic(),
+		Topic:   topic,
 		Retain:  true,
-		Payload: deviceJson,
+		Payload: configJson,
 	}
 
 	resp, err := c.internalClient.Publish(ctx, payload)
@@ -108,3 +451,74 @@ func (c *Client) UpdateTracker(ctx context.Context, report models.Report) (*paho
 
 	return resp, err
 }
+
+func (c *Client) UpdateState(ctx context.Context, uniqueId string, state string) error {
+	payload := &paho.Publish{
+		QoS:     0,
+		Topic:   models.GetStateTopic(uniqueId),
+		Payload: []byte(state),
+	}
+
+	_, err := c.internalClient.Publish(ctx, payload)
+
+	return err
+}
+
+func (c *Client) AddGeofenceSensors(ctx context.Context, device models.Device, geofences []geofence.Geofence) error {
+	var sensors []models.BinarySensor
+	for _, g := range geofences {
+		sensors = append(sensors, models.NewGeofenceSensor(device, g.Id, g.GetName()))
+	}
+
+	c.stateMu.Lock()
+	c.geofenceSensors[device.UniqueId] = sensors
+	c.stateMu.Unlock()
+
+	for _, sensor := range sensors {
+		_, err := c.publishConfig(ctx, sensor.GetConfigTopic(), sensor)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Client) PublishGeofenceEvent(ctx context.Context, event geofence.Event) error {
+	eventJson, err := json.MarshalIndent(event, "", " ")
+	if err != nil {
+		return err
+	}
+
+	payload := &paho.Publish{
+		QoS:     1,
+		Topic:   models.GetGeofenceEventTopic(event.UniqueId, event.GeofenceId),
+		Payload: eventJson,
+	}
+
+	_, err = c.internalClient.Publish(ctx, payload)
+	if err != nil {
+		return err
+	}
+
+	var state string
+	switch event.Type {
+	case geofence.EventEnter:
+		state = "ON"
+	case geofence.EventExit:
+		state = "OFF"
+	default:
+		return nil
+	}
+
+	statePayload := &paho.Publish{
+		QoS:     1,
+		Topic:   models.GetGeofenceStateTopic(event.UniqueId, event.GeofenceId),
+		Retain:  true,
+		Payload: []byte(state),
+	}
+
+	_, err = c.internalClient.Publish(ctx, statePayload)
+
+	return err
+}
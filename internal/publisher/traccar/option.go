@@ -0,0 +1,50 @@
+package traccar
+
+import (
+	"strings"
+	"time"
+)
+
+type Options struct {
+	deviceIds     map[string]string
+	queueSize     int
+	flushInterval time.Duration
+	timeout       time.Duration
+}
+
+func DefaultOptions() Options {
+	defaultOptions := Options{
+		deviceIds:     map[string]string{},
+		queueSize:     1000,
+		flushInterval: 30 * time.Second,
+		timeout:       10 * time.Second,
+	}
+
+	return defaultOptions
+}
+
+type Option func(*Options)
+
+// WithDeviceIds maps unique ids to the identifiers configured in Traccar,
+// trackers without a mapping are sent with their unique id
+func WithDeviceIds(deviceIds map[string]string) Option {
+	return func(o *Options) {
+		for uniqueId, deviceId := range deviceIds {
+			o.deviceIds[strings.ToLower(uniqueId)] = deviceId
+		}
+	}
+}
+
+// WithQueueSize sets how many positions are held while Traccar is
+// unreachable, the oldest are dropped once it is full
+func WithQueueSize(queueSize int) Option {
+	return func(o *Options) {
+		o.queueSize = queueSize
+	}
+}
+
+func WithFlushInterval(interval time.Duration) Option {
+	return func(o *Options) {
+		o.flushInterval = interval
+	}
+}
@@ -0,0 +1,259 @@
+package traccar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dylanmazurek/go-findmy/internal/publisher/models"
+	shared "github.com/dylanmazurek/go-findmy/pkg/shared/models"
+	"github.com/rs/zerolog/log"
+)
+
+// errRejected is returned when the server refuses a position, such as one for
+// an unknown device id, sending it again won't succeed
+var errRejected = errors.New("traccar rejected position")
+
+// Position is a single fix in the Traccar OsmAnd protocol
+type Position struct {
+	DeviceId  string
+	Time      time.Time
+	Latitude  float64
+	Longitude float64
+	Altitude  float64
+	Accuracy  float64
+}
+
+func (p Position) Query() url.Values {
+	query := url.Values{}
+	query.Set("id", p.DeviceId)
+	query.Set("timestamp", strconv.FormatInt(p.Time.Unix(), 10))
+	query.Set("lat", strconv.FormatFloat(p.Latitude, 'f', -1, 64))
+	query.Set("lon", strconv.FormatFloat(p.Longitude, 'f', -1, 64))
+	query.Set("altitude", strconv.FormatFloat(p.Altitude, 'f', -1, 64))
+	query.Set("accuracy", strconv.FormatFloat(p.Accuracy, 'f', -1, 64))
+
+	return query
+}
+
+// Publisher forwards reports to a Traccar server over the OsmAnd protocol.
+// Positions that can't be delivered are queued in memory and sent in order
+// once the server is reachable again.
+type Publisher struct {
+	endpoint   *url.URL
+	deviceIds  map[string]string
+	queueSize  int
+	httpClient *http.Client
+
+	mu    sync.Mutex
+	queue []Position
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewPublisher(ctx context.Context, endpoint string, opts ...Option) (*Publisher, error) {
+	publisherOptions := DefaultOptions()
+	for _, opt := range opts {
+		opt(&publisherOptions)
+	}
+
+	u, err := url.ParseRequestURI(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid traccar url: %w", err)
+	}
+
+	flushCtx, cancel := context.WithCancel(ctx)
+
+	newPublisher := &Publisher{
+		endpoint:  u,
+		deviceIds: publisherOptions.deviceIds,
+		queueSize: publisherOptions.queueSize,
+		httpClient: &http.Client{
+			Timeout: publisherOptions.timeout,
+		},
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	go newPublisher.flushLoop(flushCtx, publisherOptions.flushInterval)
+
+	return newPublisher, nil
+}
+
+// DeviceId returns the Traccar identifier for a unique id
+func (p *Publisher) DeviceId(uniqueId string) string {
+	deviceId, hasDeviceId := p.deviceIds[strings.ToLower(uniqueId)]
+	if !hasDeviceId {
+		return uniqueId
+	}
+
+	return deviceId
+}
+
+// PublishDevice is a no-op, devices are registered in Traccar itself
+func (p *Publisher) PublishDevice(ctx context.Context, device models.Device) error {
+	return nil
+}
+
+func (p *Publisher) PublishReport(ctx context.Context, report shared.LocationReport) error {
+	if report.UniqueId == nil {
+		return fmt.Errorf("report has no unique id")
+	}
+
+	// positions need coordinates, unknown semantic locations are skipped
+	if !report.HasLocation() {
+		return nil
+	}
+
+	position := Position{
+		DeviceId:  p.DeviceId(*report.UniqueId),
+		Time:      report.ReportTime,
+		Latitude:  report.Latitude,
+		Longitude: report.Longitude,
+		Altitude:  report.Altitude,
+		Accuracy:  report.Accuracy,
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.enqueue(ctx, position)
+
+	return p.flush(ctx)
+}
+
+// Pending returns the number of positions waiting to be delivered
+func (p *Publisher) Pending() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return len(p.queue)
+}
+
+func (p *Publisher) enqueue(ctx context.Context, position Position) {
+	log := log.Ctx(ctx)
+
+	p.queue = append(p.queue, position)
+
+	overflow := len(p.queue) - p.queueSize
+	if overflow > 0 {
+		log.Warn().
+			Str("publisher", "traccar").
+			Int("dropped", overflow).
+			Msg("traccar queue full, dropping oldest positions")
+
+		p.queue = p.queue[overflow:]
+	}
+}
+
+// flush sends queued positions in order, leaving any that fail in the queue.
+// Positions the server rejects are dropped so they don't hold up the rest.
+func (p *Publisher) flush(ctx context.Context) error {
+	log := log.Ctx(ctx)
+
+	for len(p.queue) > 0 {
+		err := p.send(ctx, p.queue[0])
+		if errors.Is(err, errRejected) {
+			log.Warn().
+				Err(err).
+				Str("publisher", "traccar").
+				Str("device_id", p.queue[0].DeviceId).
+				Msg("dropping rejected traccar position")
+
+			p.queue = p.queue[1:]
+			continue
+		}
+
+		if err != nil {
+			return fmt.Errorf("%d positions queued: %w", len(p.queue), err)
+		}
+
+		p.queue = p.queue[1:]
+	}
+
+	return nil
+}
+
+func (p *Publisher) send(ctx context.Context, position Position) error {
+	u := *p.endpoint
+	u.RawQuery = position.Query().Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	io.Copy(io.Discard, resp.Body)
+
+	isRejected := resp.StatusCode >= 400 && resp.StatusCode < 500 &&
+		resp.StatusCode != http.StatusRequestTimeout &&
+		resp.StatusCode != http.StatusTooManyRequests
+
+	if isRejected {
+		return fmt.Errorf("%w: traccar responded with %s", errRejected, resp.Status)
+	}
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("traccar responded with %s", resp.Status)
+	}
+
+	return nil
+}
+
+func (p *Publisher) flushLoop(ctx context.Context, interval time.Duration) {
+	log := log.Ctx(ctx).With().Str("publisher", "traccar").Logger()
+
+	defer close(p.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		p.mu.Lock()
+		err := p.flush(ctx)
+		p.mu.Unlock()
+
+		if err != nil {
+			log.Debug().Err(err).Msg("failed to flush traccar queue")
+		}
+	}
+}
+
+func (p *Publisher) Close(ctx context.Context) error {
+	p.cancel()
+
+	select {
+	case <-p.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.queue) > 0 {
+		return fmt.Errorf("%d traccar positions were not delivered", len(p.queue))
+	}
+
+	return nil
+}
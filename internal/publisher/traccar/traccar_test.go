@@ -0,0 +1,261 @@
+package traccar
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	shared "github.com/dylanmazurek/go-findmy/pkg/shared/models"
+)
+
+// osmand is a stand-in Traccar server recording every OsmAnd request
+type osmand struct {
+	mu      sync.Mutex
+	offline bool
+	unknown string
+	queries []url.Values
+}
+
+func (o *osmand) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.offline {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	if o.unknown != "" && r.URL.Query().Get("id") == o.unknown {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	o.queries = append(o.queries, r.URL.Query())
+}
+
+func (o *osmand) setOffline(offline bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.offline = offline
+}
+
+func (o *osmand) received() []url.Values {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return append([]url.Values(nil), o.queries...)
+}
+
+func newReport(uniqueId string, reportTime time.Time) shared.LocationReport {
+	return shared.LocationReport{
+		UniqueId:   &uniqueId,
+		ReportTime: reportTime,
+		Latitude:   -37.8136,
+		Longitude:  144.9631,
+		Altitude:   31.5,
+		Accuracy:   12.25,
+	}
+}
+
+func newTestPublisher(t *testing.T, opts ...Option) (*Publisher, *osmand) {
+	t.Helper()
+
+	server := &osmand{}
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+
+	opts = append([]Option{WithFlushInterval(time.Hour)}, opts...)
+
+	publisher, err := NewPublisher(context.Background(), httpServer.URL, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { publisher.Close(context.Background()) })
+
+	return publisher, server
+}
+
+func TestPublishReportFields(t *testing.T) {
+	publisher, server := newTestPublisher(t)
+
+	reportTime := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	err := publisher.PublishReport(context.Background(), newReport("abc123", reportTime))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	queries := server.received()
+	if len(queries) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(queries))
+	}
+
+	expected := map[string]string{
+		"id":        "abc123",
+		"timestamp": "1714564800",
+		"lat":       "-37.8136",
+		"lon":       "144.9631",
+		"altitude":  "31.5",
+		"accuracy":  "12.25",
+	}
+
+	for key, value := range expected {
+		if queries[0].Get(key) != value {
+			t.Errorf("expected %s=%s, got %s", key, value, queries[0].Get(key))
+		}
+	}
+}
+
+func TestPublishReportDeviceIdMapping(t *testing.T) {
+	publisher, server := newTestPublisher(t, WithDeviceIds(map[string]string{
+		"ABC123": "keys",
+	}))
+
+	ctx := context.Background()
+
+	publisher.PublishReport(ctx, newReport("abc123", time.Now()))
+	publisher.PublishReport(ctx, newReport("def456", time.Now()))
+
+	queries := server.received()
+	if len(queries) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(queries))
+	}
+
+	if queries[0].Get("id") != "keys" {
+		t.Errorf("expected mapped id, got %s", queries[0].Get("id"))
+	}
+
+	if queries[1].Get("id") != "def456" {
+		t.Errorf("expected unmapped devices to use their unique id, got %s", queries[1].Get("id"))
+	}
+}
+
+func TestPublishReportQueuesWhileOffline(t *testing.T) {
+	publisher, server := newTestPublisher(t)
+
+	ctx := context.Background()
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	server.setOffline(true)
+
+	for i := range 3 {
+		err := publisher.PublishReport(ctx, newReport("abc123", start.Add(time.Duration(i)*time.Minute)))
+		if err == nil {
+			t.Fatal("expected an error while offline")
+		}
+	}
+
+	if publisher.Pending() != 3 {
+		t.Fatalf("expected 3 queued positions, got %d", publisher.Pending())
+	}
+
+	server.setOffline(false)
+
+	err := publisher.PublishReport(ctx, newReport("abc123", start.Add(3*time.Minute)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	queries := server.received()
+	if len(queries) != 4 {
+		t.Fatalf("expected 4 delivered positions, got %d", len(queries))
+	}
+
+	for i, query := range queries {
+		expected := start.Add(time.Duration(i) * time.Minute).Unix()
+		if query.Get("timestamp") != strconv.FormatInt(expected, 10) {
+			t.Errorf("position %d delivered out of order: %s", i, query.Get("timestamp"))
+		}
+	}
+
+	if publisher.Pending() != 0 {
+		t.Fatalf("expected empty queue, got %d", publisher.Pending())
+	}
+}
+
+func TestPublishReportDropsOldestWhenFull(t *testing.T) {
+	publisher, server := newTestPublisher(t, WithQueueSize(2))
+
+	ctx := context.Background()
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	server.setOffline(true)
+
+	for i := range 3 {
+		publisher.PublishReport(ctx, newReport("abc123", start.Add(time.Duration(i)*time.Minute)))
+	}
+
+	if publisher.Pending() != 2 {
+		t.Fatalf("expected queue capped at 2, got %d", publisher.Pending())
+	}
+
+	server.setOffline(false)
+
+	publisher.mu.Lock()
+	err := publisher.flush(ctx)
+	publisher.mu.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	queries := server.received()
+	if len(queries) != 2 || queries[0].Get("timestamp") != strconv.FormatInt(start.Add(time.Minute).Unix(), 10) {
+		t.Fatalf("expected the oldest position to be dropped, got %v", queries)
+	}
+}
+
+func TestPublishReportDropsRejectedPositions(t *testing.T) {
+	publisher, server := newTestPublisher(t)
+
+	ctx := context.Background()
+
+	server.unknown = "unknown"
+	server.setOffline(true)
+
+	publisher.PublishReport(ctx, newReport("unknown", time.Now()))
+	publisher.PublishReport(ctx, newReport("abc123", time.Now()))
+
+	server.setOffline(false)
+
+	err := publisher.PublishReport(ctx, newReport("abc123", time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	queries := server.received()
+	if len(queries) != 2 {
+		t.Fatalf("expected 2 delivered positions, got %d", len(queries))
+	}
+
+	if publisher.Pending() != 0 {
+		t.Fatalf("expected the rejected position to be dropped, got %d queued", publisher.Pending())
+	}
+}
+
+func TestBackgroundFlush(t *testing.T) {
+	publisher, server := newTestPublisher(t, WithFlushInterval(10*time.Millisecond))
+
+	server.setOffline(true)
+	publisher.PublishReport(context.Background(), newReport("abc123", time.Now()))
+	server.setOffline(false)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for publisher.Pending() > 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("queued position was never flushed")
+		}
+
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if len(server.received()) != 1 {
+		t.Fatalf("expected 1 delivered position, got %d", len(server.received()))
+	}
+}
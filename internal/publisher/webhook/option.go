@@ -0,0 +1,68 @@
+package webhook
+
+import (
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+)
+
+type Options struct {
+	secret        string
+	spoolDir      *string
+	flushInterval time.Duration
+	timeout       time.Duration
+
+	newBackOff func() backoff.BackOff
+}
+
+func DefaultOptions() Options {
+	defaultOptions := Options{
+		flushInterval: time.Minute,
+		timeout:       10 * time.Second,
+
+		newBackOff: func() backoff.BackOff {
+			return backoff.NewExponentialBackOff(
+				backoff.WithInitialInterval(time.Second),
+				backoff.WithMaxInterval(30*time.Second),
+				backoff.WithMaxElapsedTime(2*time.Minute),
+			)
+		},
+	}
+
+	return defaultOptions
+}
+
+type Option func(*Options)
+
+// WithSecret signs every request body with HMAC-SHA256 using the given secret
+func WithSecret(secret string) Option {
+	return func(o *Options) {
+		o.secret = secret
+	}
+}
+
+// WithSpoolDir keeps messages that could not be delivered on disk until the
+// endpoint is reachable again
+func WithSpoolDir(dir string) Option {
+	return func(o *Options) {
+		o.spoolDir = &dir
+	}
+}
+
+func WithFlushInterval(interval time.Duration) Option {
+	return func(o *Options) {
+		o.flushInterval = interval
+	}
+}
+
+func WithMaxElapsedTime(maxElapsedTime time.Duration) Option {
+	return func(o *Options) {
+		o.newBackOff = func() backoff.BackOff {
+			return backoff.NewExponentialBackOff(
+				backoff.WithInitialInterval(time.Second),
+				backoff.WithMaxInterval(30*time.Second),
+				backoff.WithMaxElapsedTime(maxElapsedTime),
+			)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package webhook
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	spoolExtension = ".json"
+
+	// rejectedDir holds messages the endpoint refused, so they don't block
+	// the rest of the spool
+	rejectedDir = "rejected"
+)
+
+var spoolSequence atomic.Uint64
+
+// spool is a directory of undelivered message bodies, named so that a
+// lexical sort returns them in the order they were spooled
+type spool struct {
+	dir string
+}
+
+func newSpool(dir string) (*spool, error) {
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create spool directory: %w", err)
+	}
+
+	err = os.MkdirAll(filepath.Join(dir, rejectedDir), 0700)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create spool directory: %w", err)
+	}
+
+	return &spool{dir: dir}, nil
+}
+
+func (s *spool) write(body []byte) error {
+	name := fmt.Sprintf("%020d-%010d%s", time.Now().UnixNano(), spoolSequence.Add(1), spoolExtension)
+
+	tmpPath := filepath.Join(s.dir, name+".tmp")
+	err := os.WriteFile(tmpPath, body, 0600)
+	if err != nil {
+		return fmt.Errorf("unable to spool message: %w", err)
+	}
+
+	return os.Rename(tmpPath, filepath.Join(s.dir, name))
+}
+
+// reject moves a spooled message out of the way of the messages behind it
+func (s *spool) reject(path string) error {
+	return os.Rename(path, filepath.Join(s.dir, rejectedDir, filepath.Base(path)))
+}
+
+func (s *spool) list() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), spoolExtension) {
+			continue
+		}
+
+		paths = append(paths, filepath.Join(s.dir, entry.Name()))
+	}
+
+	slices.Sort(paths)
+
+	return paths, nil
+}
@@ -0,0 +1,362 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/dylanmazurek/go-findmy/internal/publisher/models"
+	shared "github.com/dylanmazurek/go-findmy/pkg/shared/models"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	HEADER_SIGNATURE = "X-Findmy-Signature-256"
+	HEADER_TIMESTAMP = "X-Findmy-Timestamp"
+
+	// queueSize is how many messages can wait for delivery without a spool
+	queueSize = 100
+)
+
+var (
+	// errRejected is returned when the endpoint refuses a message, sending it
+	// again won't succeed
+	errRejected = errors.New("webhook rejected message")
+
+	errQueueFull = errors.New("webhook delivery queue is full")
+)
+
+// Publisher POSTs every device and report as JSON to a webhook endpoint,
+// retrying with exponential backoff. Messages are delivered in the background
+// so publishing never waits on the endpoint. Without a spool they are queued
+// in memory, with a spool they are written to disk and survive restarts.
+type Publisher struct {
+	url        string
+	secret     string
+	httpClient *http.Client
+	newBackOff func() backoff.BackOff
+
+	// mu guards listing and writing the spool, it is never held while
+	// posting
+	mu    sync.Mutex
+	spool *spool
+
+	// flush wakes the flush loop when a message is spooled
+	flush chan struct{}
+
+	// queue holds messages for the deliver loop when there is no spool
+	queue     chan []byte
+	closing   chan struct{}
+	closeOnce sync.Once
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewPublisher(ctx context.Context, url string, opts ...Option) (*Publisher, error) {
+	if url == "" {
+		return nil, fmt.Errorf("webhook url is empty")
+	}
+
+	publisherOptions := DefaultOptions()
+	for _, opt := range opts {
+		opt(&publisherOptions)
+	}
+
+	newPublisher := &Publisher{
+		url:    url,
+		secret: publisherOptions.secret,
+		httpClient: &http.Client{
+			Timeout: publisherOptions.timeout,
+		},
+		newBackOff: publisherOptions.newBackOff,
+	}
+
+	loopCtx, cancel := context.WithCancel(ctx)
+	newPublisher.cancel = cancel
+	newPublisher.done = make(chan struct{})
+
+	if publisherOptions.spoolDir == nil {
+		newPublisher.queue = make(chan []byte, queueSize)
+		newPublisher.closing = make(chan struct{})
+
+		go newPublisher.deliverLoop(loopCtx)
+
+		return newPublisher, nil
+	}
+
+	newSpool, err := newSpool(*publisherOptions.spoolDir)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	newPublisher.spool = newSpool
+	newPublisher.flush = make(chan struct{}, 1)
+
+	go newPublisher.flushLoop(loopCtx, publisherOptions.flushInterval)
+
+	return newPublisher, nil
+}
+
+func (p *Publisher) PublishDevice(ctx context.Context, device models.Device) error {
+	return p.publish(ctx, models.NewDeviceMessage(device))
+}
+
+func (p *Publisher) PublishReport(ctx context.Context, report shared.LocationReport) error {
+	return p.publish(ctx, models.NewReportMessage(report))
+}
+
+// publish hands the message to the deliver loop, or spools it for the flush
+// loop, so a slow endpoint doesn't hold up other publishers
+func (p *Publisher) publish(ctx context.Context, message models.Message) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	if p.spool == nil {
+		select {
+		case p.queue <- body:
+			return nil
+		default:
+			return errQueueFull
+		}
+	}
+
+	return p.spoolMessage(body)
+}
+
+// spoolMessage writes the body to the spool and wakes the flush loop to send
+// it
+func (p *Publisher) spoolMessage(body []byte) error {
+	p.mu.Lock()
+	err := p.spool.write(body)
+	p.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	select {
+	case p.flush <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// deliver posts the body, retrying transient failures with exponential backoff
+func (p *Publisher) deliver(ctx context.Context, body []byte) error {
+	operation := func() error {
+		return p.post(ctx, body)
+	}
+
+	return backoff.Retry(operation, backoff.WithContext(p.newBackOff(), ctx))
+}
+
+// deliverLoop sends queued messages in order. Once closing, the messages
+// already queued are still sent.
+func (p *Publisher) deliverLoop(ctx context.Context) {
+	log := log.Ctx(ctx).With().Str("publisher", "webhook").Logger()
+
+	defer close(p.done)
+
+	send := func(body []byte) {
+		err := p.deliver(ctx, body)
+		if err != nil {
+			log.Warn().Err(err).Msg("failed to deliver webhook")
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case body := <-p.queue:
+			send(body)
+		case <-p.closing:
+			for {
+				select {
+				case body := <-p.queue:
+					send(body)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (p *Publisher) post(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return backoff.Permanent(err)
+	}
+
+	timestamp := fmt.Sprintf("%d", time.Now().Unix())
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HEADER_TIMESTAMP, timestamp)
+
+	if p.secret != "" {
+		req.Header.Set(HEADER_SIGNATURE, Sign(p.secret, timestamp, body))
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 300 {
+		return nil
+	}
+
+	isRetryable := resp.StatusCode >= 500 ||
+		resp.StatusCode == http.StatusRequestTimeout ||
+		resp.StatusCode == http.StatusTooManyRequests
+
+	if !isRetryable {
+		return backoff.Permanent(fmt.Errorf("%w: webhook responded with %s", errRejected, resp.Status))
+	}
+
+	return fmt.Errorf("webhook responded with %s", resp.Status)
+}
+
+// flushSpool sends spooled messages in order, stopping at the first failure.
+// Messages the endpoint rejects are moved aside so they don't hold up the
+// rest. It returns the number of messages still waiting in the spool. Only
+// the flush loop removes spooled messages, so the listed messages are its own
+// to post without holding the lock.
+func (p *Publisher) flushSpool(ctx context.Context) (int, error) {
+	log := log.Ctx(ctx).With().Str("publisher", "webhook").Logger()
+
+	p.mu.Lock()
+	paths, err := p.spool.list()
+	p.mu.Unlock()
+
+	if err != nil {
+		return 0, err
+	}
+
+	for i, path := range paths {
+		body, err := os.ReadFile(path)
+		if err != nil {
+			return len(paths) - i, err
+		}
+
+		err = p.post(ctx, body)
+		if errors.Is(err, errRejected) {
+			log.Warn().Err(err).Str("path", path).Msg("webhook rejected spooled message, moving it to the rejected spool")
+
+			err = p.spool.reject(path)
+			if err != nil {
+				return len(paths) - i, err
+			}
+
+			continue
+		}
+
+		if err != nil {
+			return len(paths) - i, err
+		}
+
+		err = os.Remove(path)
+		if err != nil {
+			return len(paths) - i - 1, err
+		}
+	}
+
+	return 0, nil
+}
+
+// flushLoop sends spooled messages when one is spooled and every interval,
+// retrying failed flushes with exponential backoff
+func (p *Publisher) flushLoop(ctx context.Context, interval time.Duration) {
+	log := log.Ctx(ctx).With().Str("publisher", "webhook").Logger()
+
+	defer close(p.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	retryBackOff := p.newBackOff()
+
+	var retry <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-p.flush:
+		case <-retry:
+		}
+
+		retry = nil
+
+		pending, err := p.flushSpool(ctx)
+
+		if err == nil {
+			retryBackOff.Reset()
+			continue
+		}
+
+		log.Debug().Err(err).Int("pending", pending).Msg("failed to flush webhook spool")
+
+		// once the backoff gives up, the spool is flushed on the next interval
+		wait := retryBackOff.NextBackOff()
+		if wait == backoff.Stop {
+			retryBackOff.Reset()
+			continue
+		}
+
+		retry = time.After(wait)
+	}
+}
+
+// Close stops the background delivery. Queued messages are still sent until
+// ctx is done, spooled messages are left for the next start.
+func (p *Publisher) Close(ctx context.Context) error {
+	defer p.cancel()
+
+	if p.closing != nil {
+		p.closeOnce.Do(func() {
+			close(p.closing)
+		})
+	} else {
+		p.cancel()
+	}
+
+	select {
+	case <-p.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	return nil
+}
+
+// Sign returns the signature header value for a request, receivers should
+// compute the same HMAC-SHA256 over the timestamp header, a ".", and the raw
+// request body, compare in constant time and reject stale timestamps
+func Sign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,302 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/dylanmazurek/go-findmy/internal/publisher/models"
+	shared "github.com/dylanmazurek/go-findmy/pkg/shared/models"
+)
+
+func withoutDelay() Option {
+	return func(o *Options) {
+		o.newBackOff = func() backoff.BackOff {
+			return backoff.WithMaxRetries(&backoff.ZeroBackOff{}, 2)
+		}
+	}
+}
+
+type receiver struct {
+	mu         sync.Mutex
+	failures   int
+	rejections int
+	bodies     [][]byte
+	headers    []http.Header
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.failures > 0 {
+		r.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	if r.rejections > 0 {
+		r.rejections--
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	body, _ := io.ReadAll(req.Body)
+	r.bodies = append(r.bodies, body)
+	r.headers = append(r.headers, req.Header.Clone())
+}
+
+func (r *receiver) reportIds(t *testing.T) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var ids []string
+	for _, body := range r.bodies {
+		var message models.Message
+		err := json.Unmarshal(body, &message)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ids = append(ids, *message.Report.UniqueId)
+	}
+
+	return ids
+}
+
+// waitForRequests waits for the flush loop to deliver count requests
+func (r *receiver) waitForRequests(t *testing.T, count int) {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		r.mu.Lock()
+		delivered := len(r.bodies)
+		r.mu.Unlock()
+
+		if delivered >= count {
+			return
+		}
+
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	t.Fatalf("timed out waiting for %d requests", count)
+}
+
+func TestPublishReportSigned(t *testing.T) {
+	ctx := context.Background()
+
+	recv := &receiver{}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+
+	publisher, err := NewPublisher(ctx, server.URL, WithSecret("secret"), withoutDelay())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer publisher.Close(ctx)
+
+	uniqueId := "tracker"
+	err = publisher.PublishReport(ctx, shared.LocationReport{UniqueId: &uniqueId})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recv.waitForRequests(t, 1)
+
+	recv.mu.Lock()
+	defer recv.mu.Unlock()
+
+	timestamp := recv.headers[0].Get(HEADER_TIMESTAMP)
+	if timestamp == "" {
+		t.Fatal("expected timestamp header")
+	}
+
+	signature := recv.headers[0].Get(HEADER_SIGNATURE)
+	if signature != Sign("secret", timestamp, recv.bodies[0]) {
+		t.Fatalf("unexpected signature %q", signature)
+	}
+}
+
+func TestPublishRetriesServerErrors(t *testing.T) {
+	ctx := context.Background()
+
+	recv := &receiver{failures: 2}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+
+	publisher, err := NewPublisher(ctx, server.URL, withoutDelay())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uniqueId := "tracker"
+	err = publisher.PublishReport(ctx, shared.LocationReport{UniqueId: &uniqueId})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// closing waits for the queued report to be delivered
+	err = publisher.Close(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(recv.bodies) != 1 {
+		t.Fatalf("expected 1 delivered request, got %d", len(recv.bodies))
+	}
+}
+
+func TestPublishDoesNotWaitForEndpoint(t *testing.T) {
+	ctx := context.Background()
+
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+
+	var publishers []*Publisher
+	for _, opts := range [][]Option{
+		{withoutDelay()},
+		{WithSpoolDir(t.TempDir()), withoutDelay()},
+	} {
+		publisher, err := NewPublisher(ctx, server.URL, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		publishers = append(publishers, publisher)
+
+		published := make(chan error, 1)
+		go func() {
+			uniqueId := "tracker"
+			published <- publisher.PublishReport(ctx, shared.LocationReport{UniqueId: &uniqueId})
+		}()
+
+		select {
+		case err = <-published:
+			if err != nil {
+				t.Fatal(err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("publishing waited for the endpoint")
+		}
+
+		publishedAgain := make(chan error, 1)
+		go func() {
+			uniqueId := "tracker"
+			publishedAgain <- publisher.PublishReport(ctx, shared.LocationReport{UniqueId: &uniqueId})
+		}()
+
+		select {
+		case err = <-publishedAgain:
+			if err != nil {
+				t.Fatal(err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("publishing waited for an earlier delivery")
+		}
+	}
+
+	close(release)
+
+	for _, publisher := range publishers {
+		publisher.Close(ctx)
+	}
+}
+
+func TestPublishSpoolsUntilEndpointRecovers(t *testing.T) {
+	ctx := context.Background()
+
+	recv := &receiver{failures: 4}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+
+	publisher, err := NewPublisher(ctx, server.URL,
+		WithSpoolDir(t.TempDir()),
+		WithFlushInterval(10*time.Millisecond),
+		withoutDelay(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer publisher.Close(ctx)
+
+	// the reports are spooled and sent in order once the endpoint recovers
+	for _, id := range []string{"first", "second", "third"} {
+		err = publisher.PublishReport(ctx, shared.LocationReport{UniqueId: &id})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	recv.waitForRequests(t, 3)
+
+	// wait for the flush loop to finish removing delivered messages
+	publisher.Close(ctx)
+
+	ids := recv.reportIds(t)
+	if len(ids) != 3 || ids[0] != "first" || ids[1] != "second" || ids[2] != "third" {
+		t.Fatalf("expected reports delivered in order, got %v", ids)
+	}
+
+	pending, err := publisher.spool.list()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(pending) != 0 {
+		t.Fatalf("expected empty spool, got %d files", len(pending))
+	}
+}
+
+func TestPublishMovesRejectedMessagesAside(t *testing.T) {
+	ctx := context.Background()
+
+	recv := &receiver{failures: 3, rejections: 1}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+
+	spoolDir := t.TempDir()
+	publisher, err := NewPublisher(ctx, server.URL,
+		WithSpoolDir(spoolDir),
+		WithFlushInterval(10*time.Millisecond),
+		withoutDelay(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer publisher.Close(ctx)
+
+	// the first report is rejected when the spool is flushed and moved aside
+	for _, id := range []string{"first", "second"} {
+		err = publisher.PublishReport(ctx, shared.LocationReport{UniqueId: &id})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	recv.waitForRequests(t, 1)
+
+	ids := recv.reportIds(t)
+	if len(ids) != 1 || ids[0] != "second" {
+		t.Fatalf("expected only the second report delivered, got %v", ids)
+	}
+
+	rejected, err := os.ReadDir(filepath.Join(spoolDir, rejectedDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rejected) != 1 {
+		t.Fatalf("expected 1 rejected message, got %d", len(rejected))
+	}
+}
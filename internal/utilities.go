@@ -2,6 +2,7 @@ package internal
 
 import (
 	"errors"
+	"runtime/debug"
 	"strings"
 
 	"github.com/dylanmazurek/go-findmy/pkg/nova/models/protos/bindings"
@@ -43,3 +44,32 @@ func FormatUniqueId(deviceMetadata *bindings.DeviceMetadata) (*string, error) {
 
 	return &uniqueId, nil
 }
+
+// FormatSpotDeviceType returns the tracker type as a lower case name, such as
+// keys or wallet
+func FormatSpotDeviceType(deviceType bindings.SpotDeviceType) string {
+	name := strings.TrimPrefix(deviceType.String(), "DEVICE_TYPE_")
+
+	return strings.ToLower(name)
+}
+
+// Version returns the module version, or the VCS revision for builds from a
+// source checkout
+func Version() string {
+	buildInfo, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+
+	if buildInfo.Main.Version != "" && buildInfo.Main.Version != "(devel)" {
+		return buildInfo.Main.Version
+	}
+
+	for _, setting := range buildInfo.Settings {
+		if setting.Key == "vcs.revision" && len(setting.Value) >= 7 {
+			return setting.Value[:7]
+		}
+	}
+
+	return "devel"
+}
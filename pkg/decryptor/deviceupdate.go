@@ -8,6 +8,7 @@ import (
 	"github.com/dylanmazurek/go-findmy/pkg/nova/models/protos/bindings"
 	"github.com/dylanmazurek/go-findmy/pkg/shared/models"
 	"github.com/rs/zerolog/log"
+	"github.com/zeebo/xxh3"
 )
 
 func (d *Decryptor) DecryptDeviceUpdate(ctx context.Context, deviceUpdate *bindings.DeviceUpdate) ([]models.LocationReport, error) {
@@ -73,8 +74,36 @@ func (d *Decryptor) DecryptDeviceUpdate(ctx context.Context, deviceUpdate *bindi
 		}
 
 		location.ReportTime = reportTime
+		location.Status = reportStatus(netLoc.GetStatus())
+		location.PayloadHash = payloadHash(netLoc)
 		locations = append(locations, location)
 	}
 
 	return locations, nil
 }
+
+func reportStatus(status bindings.Status) models.ReportStatus {
+	switch status {
+	case bindings.Status_LAST_KNOWN:
+		return models.ReportStatusLastKnown
+	case bindings.Status_CROWDSOURCED:
+		return models.ReportStatusCrowdsourced
+	case bindings.Status_AGGREGATED:
+		return models.ReportStatusAggregated
+	}
+
+	return models.ReportStatusSemantic
+}
+
+func payloadHash(loc *bindings.LocationReport) string {
+	hasher := xxh3.New()
+
+	encryptedReport := loc.GetGeoLocation().GetEncryptedReport()
+	hasher.Write(encryptedReport.GetPublicKeyRandom())
+	hasher.Write(encryptedReport.GetEncryptedLocation())
+	hasher.WriteString(loc.GetSemanticLocation().GetLocationName())
+
+	sum := hasher.Sum128().Bytes()
+
+	return hex.EncodeToString(sum[:])
+}
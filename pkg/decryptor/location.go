@@ -32,8 +32,10 @@ func decryptSemantic(loc *bindings.LocationReport) (*models.LocationReport, erro
 }
 
 func decryptReport(loc *bindings.LocationReport, identityKey []byte) (*models.LocationReport, error) {
-	encryptedLocation := loc.GetGeoLocation().GetEncryptedReport().GetEncryptedLocation()
-	publicKeyRandom := loc.GetGeoLocation().GetEncryptedReport().GetPublicKeyRandom()
+	geoLocation := loc.GetGeoLocation()
+
+	encryptedLocation := geoLocation.GetEncryptedReport().GetEncryptedLocation()
+	publicKeyRandom := geoLocation.GetEncryptedReport().GetPublicKeyRandom()
 	hasPublicKey := len(publicKeyRandom) != 0
 
 	var decryptedLocation []byte
@@ -46,7 +48,7 @@ func decryptReport(loc *bindings.LocationReport, identityKey []byte) (*models.Lo
 
 		decryptedLocation = location
 	} else {
-		beaconTimerCounter := loc.GetGeoLocation().DeviceTimeOffset
+		beaconTimerCounter := geoLocation.GetDeviceTimeOffset()
 		decryptedLocation, err = decryptLocationWithPublicKey(encryptedLocation, publicKeyRandom, beaconTimerCounter, identityKey)
 		if err != nil {
 			return nil, err
@@ -60,10 +62,13 @@ func decryptReport(loc *bindings.LocationReport, identityKey []byte) (*models.Lo
 	}
 
 	newLocation := &models.LocationReport{
-		ReportType: models.ReportTypeLocation,
-		Latitude:   float64(protoLoc.GetLatitude()) / 1e7,
-		Longitude:  float64(protoLoc.GetLongitude()) / 1e7,
-		Altitude:   float64(protoLoc.GetAltitude()),
+		ReportType:       models.ReportTypeLocation,
+		Latitude:         float64(protoLoc.GetLatitude()) / 1e7,
+		Longitude:        float64(protoLoc.GetLongitude()) / 1e7,
+		Altitude:         float64(protoLoc.GetAltitude()),
+		Accuracy:         float64(geoLocation.GetAccuracy()),
+		DeviceTimeOffset: geoLocation.GetDeviceTimeOffset(),
+		IsOwnReport:      geoLocation.GetEncryptedReport().GetIsOwnReport(),
 	}
 
 	return newLocation, nil
@@ -0,0 +1,71 @@
+package decryptor
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/dylanmazurek/go-findmy/pkg/nova/models/protos/bindings"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestDecryptReportMetadata(t *testing.T) {
+	identityKey := []byte("0123456789abcdef0123456789abcdef")
+
+	location, err := proto.Marshal(&bindings.Location{
+		Latitude:  -378136000,
+		Longitude: 1449631000,
+		Altitude:  31,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	identityKeyHash := sha256.Sum256(identityKey)
+	block, err := aes.NewCipher(identityKeyHash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	aesgcm, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	iv := make([]byte, 12)
+	encryptedLocation := aesgcm.Seal(iv, iv, location, nil)
+
+	loc := &bindings.LocationReport{
+		Status: bindings.Status_CROWDSOURCED,
+		GeoLocation: &bindings.GeoLocation{
+			EncryptedReport: &bindings.EncryptedReport{
+				EncryptedLocation: encryptedLocation,
+				IsOwnReport:       true,
+			},
+			DeviceTimeOffset: 1024,
+			Accuracy:         12.5,
+		},
+	}
+
+	report, err := decryptReport(loc, identityKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Latitude != -37.8136 || report.Longitude != 144.9631 || report.Altitude != 31 {
+		t.Errorf("unexpected location %f, %f, %f", report.Latitude, report.Longitude, report.Altitude)
+	}
+
+	if report.Accuracy != 12.5 {
+		t.Errorf("expected the report's own accuracy, got %f", report.Accuracy)
+	}
+
+	if report.DeviceTimeOffset != 1024 {
+		t.Errorf("expected device time offset 1024, got %d", report.DeviceTimeOffset)
+	}
+
+	if !report.IsOwnReport {
+		t.Error("expected report to be marked as own")
+	}
+}
@@ -0,0 +1,223 @@
+package dedup
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	shared "github.com/dylanmazurek/go-findmy/pkg/shared/models"
+	"github.com/rs/zerolog/log"
+)
+
+// Deduplicator remembers which location reports have already been seen so
+// repeated FCM pushes for the same report are only handled once. Entries are
+// evicted least recently used first once the capacity is reached, and expire
+// after the configured ttl.
+type Deduplicator struct {
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+
+	capacity        int
+	ttl             time.Duration
+	persistencePath *string
+	dirty           bool
+
+	now func() time.Time
+}
+
+type entry struct {
+	Key       string    `json:"key"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+func NewDeduplicator(ctx context.Context, opts ...Option) (*Deduplicator, error) {
+	log := log.Ctx(ctx).With().Str("client", "dedup").Logger()
+
+	dedupOptions := DefaultOptions()
+	for _, opt := range opts {
+		opt(&dedupOptions)
+	}
+
+	if dedupOptions.capacity <= 0 {
+		return nil, fmt.Errorf("invalid dedup capacity: %d", dedupOptions.capacity)
+	}
+
+	newDeduplicator := &Deduplicator{
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+
+		capacity:        dedupOptions.capacity,
+		ttl:             dedupOptions.ttl,
+		persistencePath: dedupOptions.persistencePath,
+
+		now: time.Now,
+	}
+
+	if newDeduplicator.persistencePath != nil {
+		count, err := newDeduplicator.load()
+		if err != nil {
+			return nil, err
+		}
+
+		log.Debug().Int("count", count).Msg("loaded dedup state")
+	}
+
+	return newDeduplicator, nil
+}
+
+func Key(report shared.LocationReport) string {
+	var uniqueId string
+	if report.UniqueId != nil {
+		uniqueId = *report.UniqueId
+	}
+
+	key := fmt.Sprintf("%s:%d:%s", uniqueId, report.ReportTime.Unix(), report.PayloadHash)
+
+	return key
+}
+
+// Filter returns the reports that have not been seen before and marks them as seen
+func (d *Deduplicator) Filter(reports []shared.LocationReport) []shared.LocationReport {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+
+	var newReports []shared.LocationReport
+	for _, report := range reports {
+		if d.seen(Key(report), now) {
+			continue
+		}
+
+		newReports = append(newReports, report)
+	}
+
+	return newReports
+}
+
+func (d *Deduplicator) seen(key string, now time.Time) bool {
+	expiresAt := now.Add(d.ttl)
+
+	element, ok := d.entries[key]
+	if ok {
+		e := element.Value.(*entry)
+		if now.Before(e.ExpiresAt) {
+			d.order.MoveToFront(element)
+			return true
+		}
+
+		e.ExpiresAt = expiresAt
+		d.order.MoveToFront(element)
+		d.dirty = true
+
+		return false
+	}
+
+	d.entries[key] = d.order.PushFront(&entry{Key: key, ExpiresAt: expiresAt})
+	d.dirty = true
+
+	for d.order.Len() > d.capacity {
+		oldest := d.order.Back()
+		d.order.Remove(oldest)
+		delete(d.entries, oldest.Value.(*entry).Key)
+	}
+
+	return false
+}
+
+func (d *Deduplicator) Len() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.order.Len()
+}
+
+// Save writes the current state to the persistence path, if one is configured
+func (d *Deduplicator) Save() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.persistencePath == nil || !d.dirty {
+		return nil
+	}
+
+	now := d.now()
+
+	var entries []entry
+	for element := d.order.Back(); element != nil; element = element.Prev() {
+		e := element.Value.(*entry)
+		if !now.Before(e.ExpiresAt) {
+			continue
+		}
+
+		entries = append(entries, *e)
+	}
+
+	entriesJson, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	path := *d.persistencePath
+
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return fmt.Errorf("unable to create dedup directory: %w", err)
+	}
+
+	tmpPath := path + ".tmp"
+	err = os.WriteFile(tmpPath, entriesJson, 0644)
+	if err != nil {
+		return fmt.Errorf("unable to write dedup state: %w", err)
+	}
+
+	err = os.Rename(tmpPath, path)
+	if err != nil {
+		return fmt.Errorf("unable to replace dedup state: %w", err)
+	}
+
+	d.dirty = false
+
+	return nil
+}
+
+func (d *Deduplicator) load() (int, error) {
+	entriesJson, err := os.ReadFile(*d.persistencePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("unable to read dedup state: %w", err)
+	}
+
+	var entries []entry
+	err = json.Unmarshal(entriesJson, &entries)
+	if err != nil {
+		return 0, fmt.Errorf("unable to parse dedup state: %w", err)
+	}
+
+	now := d.now()
+	for _, e := range entries {
+		if !now.Before(e.ExpiresAt) {
+			continue
+		}
+
+		d.entries[e.Key] = d.order.PushFront(&entry{Key: e.Key, ExpiresAt: e.ExpiresAt})
+	}
+
+	for d.order.Len() > d.capacity {
+		oldest := d.order.Back()
+		d.order.Remove(oldest)
+		delete(d.entries, oldest.Value.(*entry).Key)
+	}
+
+	return d.order.Len(), nil
+}
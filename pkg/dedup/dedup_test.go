@@ -0,0 +1,102 @@
+package dedup
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	shared "github.com/dylanmazurek/go-findmy/pkg/shared/models"
+)
+
+func newReport(uniqueId string, reportTime time.Time, payloadHash string) shared.LocationReport {
+	return shared.LocationReport{
+		UniqueId:    &uniqueId,
+		ReportTime:  reportTime,
+		PayloadHash: payloadHash,
+	}
+}
+
+func TestFilter(t *testing.T) {
+	d, err := NewDeduplicator(context.Background())
+	if err != nil {
+		t.Fatalf("NewDeduplicator: %v", err)
+	}
+
+	base := time.Unix(1700000000, 0)
+	reports := []shared.LocationReport{
+		newReport("tracker-a", base, "aa"),
+		newReport("tracker-a", base, "bb"),
+		newReport("tracker-b", base, "aa"),
+	}
+
+	newReports := d.Filter(reports)
+	if len(newReports) != 3 {
+		t.Fatalf("Filter: expected 3 new reports on first push, got %d", len(newReports))
+	}
+
+	reports = append(reports, newReport("tracker-a", base.Add(time.Minute), "cc"))
+
+	newReports = d.Filter(reports)
+	if len(newReports) != 1 {
+		t.Fatalf("Filter: expected 1 new report on repeated push, got %d", len(newReports))
+	}
+
+	if newReports[0].PayloadHash != "cc" {
+		t.Errorf("Filter: expected report cc, got %s", newReports[0].PayloadHash)
+	}
+}
+
+func TestFilterEvictsAndExpires(t *testing.T) {
+	d, err := NewDeduplicator(context.Background(), WithCapacity(2), WithTTL(time.Hour))
+	if err != nil {
+		t.Fatalf("NewDeduplicator: %v", err)
+	}
+
+	now := time.Unix(1700000000, 0)
+	d.now = func() time.Time { return now }
+
+	base := time.Unix(1600000000, 0)
+	first := newReport("tracker-a", base, "aa")
+
+	d.Filter([]shared.LocationReport{first, newReport("tracker-a", base, "bb"), newReport("tracker-a", base, "cc")})
+	if d.Len() != 2 {
+		t.Fatalf("Len: expected capacity of 2, got %d", d.Len())
+	}
+
+	if len(d.Filter([]shared.LocationReport{first})) != 1 {
+		t.Errorf("Filter: expected least recently used report to have been evicted")
+	}
+
+	now = now.Add(2 * time.Hour)
+	if len(d.Filter([]shared.LocationReport{first})) != 1 {
+		t.Errorf("Filter: expected expired report to be treated as new")
+	}
+}
+
+func TestPersistence(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "dedup.json")
+
+	d, err := NewDeduplicator(ctx, WithPersistence(path))
+	if err != nil {
+		t.Fatalf("NewDeduplicator: %v", err)
+	}
+
+	report := newReport("tracker-a", time.Unix(1700000000, 0), "aa")
+	d.Filter([]shared.LocationReport{report})
+
+	err = d.Save()
+	if err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	d, err = NewDeduplicator(ctx, WithPersistence(path))
+	if err != nil {
+		t.Fatalf("NewDeduplicator (reload): %v", err)
+	}
+
+	if len(d.Filter([]shared.LocationReport{report})) != 0 {
+		t.Errorf("Filter: expected report to be remembered across restarts")
+	}
+}
@@ -0,0 +1,38 @@
+package dedup
+
+import "time"
+
+type Options struct {
+	capacity        int
+	ttl             time.Duration
+	persistencePath *string
+}
+
+func DefaultOptions() Options {
+	defaultOptions := Options{
+		capacity: 4096,
+		ttl:      24 * time.Hour,
+	}
+
+	return defaultOptions
+}
+
+type Option func(*Options)
+
+func WithCapacity(capacity int) Option {
+	return func(o *Options) {
+		o.capacity = capacity
+	}
+}
+
+func WithTTL(ttl time.Duration) Option {
+	return func(o *Options) {
+		o.ttl = ttl
+	}
+}
+
+func WithPersistence(path string) Option {
+	return func(o *Options) {
+		o.persistencePath = &path
+	}
+}
@@ -0,0 +1,83 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	shared "github.com/dylanmazurek/go-findmy/pkg/shared/models"
+)
+
+type Format string
+
+const (
+	FormatGPX     Format = "gpx"
+	FormatKML     Format = "kml"
+	FormatGeoJSON Format = "geojson"
+)
+
+func ParseFormat(format string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimSpace(format))) {
+	case FormatGPX:
+		return FormatGPX, nil
+	case FormatKML:
+		return FormatKML, nil
+	case FormatGeoJSON, "json":
+		return FormatGeoJSON, nil
+	}
+
+	return "", fmt.Errorf("unknown export format: %s", format)
+}
+
+// Track is the location history of a single device
+type Track struct {
+	UniqueId string
+	Name     string
+	Reports  []shared.LocationReport
+}
+
+// NewTrack sorts the reports by time, leaving out semantic reports without
+// coordinates
+func NewTrack(uniqueId string, name string, reports []shared.LocationReport) Track {
+	sortedReports := slices.DeleteFunc(slices.Clone(reports), func(report shared.LocationReport) bool {
+		return !report.HasLocation()
+	})
+
+	slices.SortStableFunc(sortedReports, func(a, b shared.LocationReport) int {
+		return a.ReportTime.Compare(b.ReportTime)
+	})
+
+	if name == "" {
+		name = uniqueId
+	}
+
+	newTrack := Track{
+		UniqueId: uniqueId,
+		Name:     name,
+		Reports:  sortedReports,
+	}
+
+	return newTrack
+}
+
+func Write(w io.Writer, format Format, tracks []Track) error {
+	switch format {
+	case FormatGPX:
+		return WriteGPX(w, tracks)
+	case FormatKML:
+		return WriteKML(w, tracks)
+	case FormatGeoJSON:
+		return WriteGeoJSON(w, tracks)
+	}
+
+	return fmt.Errorf("unknown export format: %s", format)
+}
+
+func semanticName(report shared.LocationReport) string {
+	if report.SemanticName == nil {
+		return ""
+	}
+
+	return *report.SemanticName
+}
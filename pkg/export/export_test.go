@@ -0,0 +1,155 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	shared "github.com/dylanmazurek/go-findmy/pkg/shared/models"
+)
+
+func testTracks() []Track {
+	uniqueId := "abc123"
+	semanticName := "Home"
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	reports := []shared.LocationReport{
+		{
+			UniqueId:   &uniqueId,
+			ReportType: shared.ReportTypeLocation,
+			ReportTime: start.Add(time.Minute),
+			Status:     shared.ReportStatusCrowdsourced,
+			Latitude:   -37.81,
+			Longitude:  144.96,
+			Accuracy:   18.5,
+		},
+		{
+			UniqueId:     &uniqueId,
+			ReportType:   shared.ReportTypeSemantic,
+			ReportTime:   start,
+			Status:       shared.ReportStatusSemantic,
+			Latitude:     -37.80,
+			Longitude:    144.95,
+			Accuracy:     4,
+			SemanticName: &semanticName,
+		},
+	}
+
+	return []Track{NewTrack(uniqueId, "Keys", reports)}
+}
+
+func TestWriteGeoJSON(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := Write(&buf, FormatGeoJSON, testTracks())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var collection featureCollection
+	err = json.Unmarshal(buf.Bytes(), &collection)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if collection.Type != "FeatureCollection" || len(collection.Features) != 2 {
+		t.Fatalf("unexpected collection %+v", collection)
+	}
+
+	first := collection.Features[0]
+	if first.Properties.Status != "semantic" || first.Properties.SemanticName == nil || *first.Properties.SemanticName != "Home" {
+		t.Fatalf("expected reports sorted by time with semantic name, got %+v", first.Properties)
+	}
+
+	second := collection.Features[1]
+	if second.Properties.Status != "crowdsourced" || second.Properties.Accuracy != 18.5 {
+		t.Fatalf("unexpected properties %+v", second.Properties)
+	}
+
+	if second.Geometry.Coordinates[0] != 144.96 || second.Geometry.Coordinates[1] != -37.81 {
+		t.Fatalf("expected lon,lat coordinates, got %v", second.Geometry.Coordinates)
+	}
+}
+
+func TestWriteGPX(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := Write(&buf, FormatGPX, testTracks())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc struct {
+		Tracks []struct {
+			Name   string `xml:"name"`
+			Points []struct {
+				Latitude    float64 `xml:"lat,attr"`
+				Time        string  `xml:"time"`
+				Description string  `xml:"desc"`
+				Type        string  `xml:"type"`
+				Accuracy    float64 `xml:"extensions>accuracy"`
+			} `xml:"trkseg>trkpt"`
+		} `xml:"trk"`
+	}
+
+	err = xml.Unmarshal(buf.Bytes(), &doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(doc.Tracks) != 1 || doc.Tracks[0].Name != "Keys" || len(doc.Tracks[0].Points) != 2 {
+		t.Fatalf("unexpected gpx %+v", doc)
+	}
+
+	points := doc.Tracks[0].Points
+	if points[0].Description != "Home" || points[0].Type != "semantic" {
+		t.Fatalf("unexpected first point %+v", points[0])
+	}
+
+	if points[1].Type != "crowdsourced" || points[1].Accuracy != 18.5 || points[1].Time != "2024-05-01T12:01:00Z" {
+		t.Fatalf("unexpected second point %+v", points[1])
+	}
+}
+
+func TestWriteKML(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := Write(&buf, FormatKML, testTracks())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc kml
+	err = xml.Unmarshal(buf.Bytes(), &doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(doc.Document.Folders) != 1 || len(doc.Document.Folders[0].Placemarks) != 2 {
+		t.Fatalf("unexpected kml %+v", doc)
+	}
+
+	placemark := doc.Document.Folders[0].Placemarks[1]
+	if placemark.Point.Coordinates != "144.96,-37.81,0" {
+		t.Fatalf("unexpected coordinates %s", placemark.Point.Coordinates)
+	}
+
+	if !strings.Contains(buf.String(), "<value>crowdsourced</value>") {
+		t.Fatal("expected report status in extended data")
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat(" GeoJSON ")
+	if err != nil || format != FormatGeoJSON {
+		t.Fatalf("expected geojson, got %s %v", format, err)
+	}
+
+	_, err = ParseFormat("csv")
+	if err == nil {
+		t.Fatal("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,67 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+type featureCollection struct {
+	Type     string    `json:"type"`
+	Features []feature `json:"features"`
+}
+
+type feature struct {
+	Type       string            `json:"type"`
+	Geometry   geometry          `json:"geometry"`
+	Properties featureProperties `json:"properties"`
+}
+
+type geometry struct {
+	Type        string    `json:"type"`
+	Coordinates []float64 `json:"coordinates"`
+}
+
+type featureProperties struct {
+	UniqueId     string    `json:"unique_id"`
+	Name         string    `json:"name"`
+	ReportTime   time.Time `json:"report_time"`
+	ReportType   string    `json:"report_type"`
+	Status       string    `json:"status"`
+	Accuracy     float64   `json:"accuracy"`
+	SemanticName *string   `json:"semantic_name,omitempty"`
+}
+
+// WriteGeoJSON writes a FeatureCollection with a Point feature per report
+func WriteGeoJSON(w io.Writer, tracks []Track) error {
+	collection := featureCollection{
+		Type:     "FeatureCollection",
+		Features: []feature{},
+	}
+
+	for _, track := range tracks {
+		for _, report := range track.Reports {
+			collection.Features = append(collection.Features, feature{
+				Type: "Feature",
+				Geometry: geometry{
+					Type:        "Point",
+					Coordinates: []float64{report.Longitude, report.Latitude, report.Altitude},
+				},
+				Properties: featureProperties{
+					UniqueId:     track.UniqueId,
+					Name:         track.Name,
+					ReportTime:   report.ReportTime.UTC(),
+					ReportType:   report.ReportType.String(),
+					Status:       report.Status.String(),
+					Accuracy:     report.Accuracy,
+					SemanticName: report.SemanticName,
+				},
+			})
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(collection)
+}
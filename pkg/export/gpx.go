@@ -0,0 +1,102 @@
+package export
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+const (
+	GPX_NAMESPACE        = "http://www.topografix.com/GPX/1/1"
+	GPX_FINDMY_NAMESPACE = "https://github.com/dylanmazurek/go-findmy/gpx/1"
+)
+
+type gpx struct {
+	XMLName   xml.Name   `xml:"gpx"`
+	Version   string     `xml:"version,attr"`
+	Creator   string     `xml:"creator,attr"`
+	Namespace string     `xml:"xmlns,attr"`
+	FindMyNs  string     `xml:"xmlns:findmy,attr"`
+	Tracks    []gpxTrack `xml:"trk"`
+}
+
+type gpxTrack struct {
+	Name     string       `xml:"name"`
+	Source   string       `xml:"src"`
+	Segments []gpxSegment `xml:"trkseg"`
+}
+
+type gpxSegment struct {
+	Points []gpxPoint `xml:"trkpt"`
+}
+
+// gpxPoint carries the report status as the point type, the semantic name as
+// its description and the accuracy in a findmy extension
+type gpxPoint struct {
+	Latitude    float64       `xml:"lat,attr"`
+	Longitude   float64       `xml:"lon,attr"`
+	Elevation   float64       `xml:"ele"`
+	Time        string        `xml:"time"`
+	Description string        `xml:"desc,omitempty"`
+	Type        string        `xml:"type"`
+	Extensions  gpxExtensions `xml:"extensions"`
+}
+
+type gpxExtensions struct {
+	Accuracy   float64 `xml:"findmy:accuracy"`
+	ReportType string  `xml:"findmy:report_type"`
+}
+
+func WriteGPX(w io.Writer, tracks []Track) error {
+	doc := gpx{
+		Version:   "1.1",
+		Creator:   "go-findmy",
+		Namespace: GPX_NAMESPACE,
+		FindMyNs:  GPX_FINDMY_NAMESPACE,
+	}
+
+	for _, track := range tracks {
+		segment := gpxSegment{}
+		for _, report := range track.Reports {
+			segment.Points = append(segment.Points, gpxPoint{
+				Latitude:    report.Latitude,
+				Longitude:   report.Longitude,
+				Elevation:   report.Altitude,
+				Time:        report.ReportTime.UTC().Format(time.RFC3339),
+				Description: semanticName(report),
+				Type:        report.Status.String(),
+				Extensions: gpxExtensions{
+					Accuracy:   report.Accuracy,
+					ReportType: report.ReportType.String(),
+				},
+			})
+		}
+
+		doc.Tracks = append(doc.Tracks, gpxTrack{
+			Name:     track.Name,
+			Source:   track.UniqueId,
+			Segments: []gpxSegment{segment},
+		})
+	}
+
+	return writeXml(w, doc)
+}
+
+func writeXml(w io.Writer, doc any) error {
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+
+	err = encoder.Encode(doc)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "\n")
+
+	return err
+}
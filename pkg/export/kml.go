@@ -0,0 +1,101 @@
+package export
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+const (
+	KML_NAMESPACE = "http://www.opengis.net/kml/2.2"
+)
+
+type kml struct {
+	XMLName   xml.Name    `xml:"kml"`
+	Namespace string      `xml:"xmlns,attr"`
+	Document  kmlDocument `xml:"Document"`
+}
+
+type kmlDocument struct {
+	Name    string      `xml:"name"`
+	Folders []kmlFolder `xml:"Folder"`
+}
+
+type kmlFolder struct {
+	Name       string         `xml:"name"`
+	Placemarks []kmlPlacemark `xml:"Placemark"`
+}
+
+type kmlPlacemark struct {
+	Name         string          `xml:"name"`
+	Description  string          `xml:"description,omitempty"`
+	TimeStamp    kmlTimeStamp    `xml:"TimeStamp"`
+	ExtendedData kmlExtendedData `xml:"ExtendedData"`
+	Point        kmlPoint        `xml:"Point"`
+}
+
+type kmlTimeStamp struct {
+	When string `xml:"when"`
+}
+
+type kmlExtendedData struct {
+	Data []kmlData `xml:"Data"`
+}
+
+type kmlData struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value"`
+}
+
+type kmlPoint struct {
+	Coordinates string `xml:"coordinates"`
+}
+
+func WriteKML(w io.Writer, tracks []Track) error {
+	doc := kml{
+		Namespace: KML_NAMESPACE,
+		Document: kmlDocument{
+			Name: "go-findmy",
+		},
+	}
+
+	for _, track := range tracks {
+		folder := kmlFolder{
+			Name: track.Name,
+		}
+
+		for _, report := range track.Reports {
+			reportTime := report.ReportTime.UTC().Format(time.RFC3339)
+
+			folder.Placemarks = append(folder.Placemarks, kmlPlacemark{
+				Name:        reportTime,
+				Description: semanticName(report),
+				TimeStamp: kmlTimeStamp{
+					When: reportTime,
+				},
+				ExtendedData: kmlExtendedData{
+					Data: []kmlData{
+						{Name: "unique_id", Value: track.UniqueId},
+						{Name: "status", Value: report.Status.String()},
+						{Name: "report_type", Value: report.ReportType.String()},
+						{Name: "accuracy", Value: strconv.FormatFloat(report.Accuracy, 'f', -1, 64)},
+						{Name: "semantic_name", Value: semanticName(report)},
+					},
+				},
+				Point: kmlPoint{
+					Coordinates: fmt.Sprintf("%s,%s,%s",
+						strconv.FormatFloat(report.Longitude, 'f', -1, 64),
+						strconv.FormatFloat(report.Latitude, 'f', -1, 64),
+						strconv.FormatFloat(report.Altitude, 'f', -1, 64),
+					),
+				},
+			})
+		}
+
+		doc.Document.Folders = append(doc.Document.Folders, folder)
+	}
+
+	return writeXml(w, doc)
+}
@@ -0,0 +1,193 @@
+package geofence
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	shared "github.com/dylanmazurek/go-findmy/pkg/shared/models"
+)
+
+type EventType string
+
+const (
+	EventEnter EventType = "enter"
+	EventExit  EventType = "exit"
+	EventDwell EventType = "dwell"
+)
+
+type Event struct {
+	Type         EventType `json:"event"`
+	UniqueId     string    `json:"unique_id"`
+	GeofenceId   string    `json:"geofence_id"`
+	GeofenceName string    `json:"geofence_name"`
+	Time         time.Time `json:"time"`
+	Latitude     float64   `json:"latitude"`
+	Longitude    float64   `json:"longitude"`
+	Accuracy     float64   `json:"accuracy"`
+}
+
+type state struct {
+	inside    bool
+	enteredAt time.Time
+	dwelled   bool
+}
+
+type deviceState struct {
+	lastReportTime time.Time
+	geofences      map[string]*state
+}
+
+// Engine tracks which geofences every device is in. A device enters a geofence
+// as soon as the centre of a fix is inside it, but only exits once the whole
+// accuracy circle is outside, so noisy fixes near the boundary don't flap.
+type Engine struct {
+	mu sync.Mutex
+
+	geofences   []Geofence
+	maxAccuracy float64
+
+	devices map[string]*deviceState
+}
+
+func NewEngine(geofences []Geofence, opts ...Option) (*Engine, error) {
+	engineOptions := DefaultOptions()
+	for _, opt := range opts {
+		opt(&engineOptions)
+	}
+
+	seen := make(map[string]bool)
+	for _, g := range geofences {
+		err := g.Validate()
+		if err != nil {
+			return nil, err
+		}
+
+		if seen[g.Id] {
+			return nil, fmt.Errorf("duplicate geofence id: %s", g.Id)
+		}
+
+		seen[g.Id] = true
+	}
+
+	newEngine := &Engine{
+		geofences:   geofences,
+		maxAccuracy: engineOptions.maxAccuracy,
+
+		devices: make(map[string]*deviceState),
+	}
+
+	return newEngine, nil
+}
+
+func (e *Engine) Geofences() []Geofence {
+	return e.geofences
+}
+
+// Evaluate updates the state of the reporting device and returns any resulting
+// events. Reports older than the last evaluated report for the device, or less
+// accurate than the configured limit, are ignored.
+func (e *Engine) Evaluate(report shared.LocationReport) []Event {
+	if report.UniqueId == nil {
+		return nil
+	}
+
+	if !report.HasLocation() {
+		return nil
+	}
+
+	if e.maxAccuracy > 0 && report.Accuracy > e.maxAccuracy {
+		return nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	uniqueId := *report.UniqueId
+
+	device, ok := e.devices[uniqueId]
+	if !ok {
+		device = &deviceState{
+			geofences: make(map[string]*state),
+		}
+
+		e.devices[uniqueId] = device
+	}
+
+	if report.ReportTime.Before(device.lastReportTime) {
+		return nil
+	}
+
+	device.lastReportTime = report.ReportTime
+
+	point := Point{
+		Latitude:  report.Latitude,
+		Longitude: report.Longitude,
+	}
+
+	newEvent := func(eventType EventType, g Geofence) Event {
+		return Event{
+			Type:         eventType,
+			UniqueId:     uniqueId,
+			GeofenceId:   g.Id,
+			GeofenceName: g.GetName(),
+			Time:         report.ReportTime,
+			Latitude:     report.Latitude,
+			Longitude:    report.Longitude,
+			Accuracy:     report.Accuracy,
+		}
+	}
+
+	var events []Event
+	for _, g := range e.geofences {
+		s, known := device.geofences[g.Id]
+		if !known {
+			s = &state{}
+			device.geofences[g.Id] = s
+		}
+
+		isInside := g.Contains(point)
+
+		switch {
+		case !s.inside && isInside:
+			s.inside = true
+			s.enteredAt = report.ReportTime
+			s.dwelled = false
+
+			events = append(events, newEvent(EventEnter, g))
+		case s.inside && !isInside && g.BoundaryDistance(point) > report.Accuracy:
+			s.inside = false
+
+			events = append(events, newEvent(EventExit, g))
+		}
+
+		if !s.inside || s.dwelled || g.DwellSeconds <= 0 {
+			continue
+		}
+
+		if report.ReportTime.Sub(s.enteredAt) >= g.Dwell() {
+			s.dwelled = true
+
+			events = append(events, newEvent(EventDwell, g))
+		}
+	}
+
+	return events
+}
+
+func (e *Engine) IsInside(uniqueId string, geofenceId string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	device, ok := e.devices[uniqueId]
+	if !ok {
+		return false
+	}
+
+	s, ok := device.geofences[geofenceId]
+	if !ok {
+		return false
+	}
+
+	return s.inside
+}
@@ -0,0 +1,124 @@
+package geofence
+
+import (
+	"testing"
+	"time"
+
+	shared "github.com/dylanmazurek/go-findmy/pkg/shared/models"
+)
+
+var home = Geofence{
+	Id:           "home",
+	Name:         "Home",
+	Latitude:     -37.8136,
+	Longitude:    144.9631,
+	Radius:       100,
+	DwellSeconds: 600,
+}
+
+var park = Geofence{
+	Id: "park",
+	Polygon: []Point{
+		{Latitude: -37.8200, Longitude: 144.9700},
+		{Latitude: -37.8200, Longitude: 144.9800},
+		{Latitude: -37.8300, Longitude: 144.9800},
+		{Latitude: -37.8300, Longitude: 144.9700},
+	},
+}
+
+func newReport(reportTime time.Time, latitude float64, longitude float64, accuracy float64) shared.LocationReport {
+	uniqueId := "tracker-a"
+
+	return shared.LocationReport{
+		UniqueId:   &uniqueId,
+		ReportType: shared.ReportTypeLocation,
+		ReportTime: reportTime,
+		Latitude:   latitude,
+		Longitude:  longitude,
+		Accuracy:   accuracy,
+	}
+}
+
+func eventTypes(events []Event) []EventType {
+	var types []EventType
+	for _, event := range events {
+		types = append(types, event.Type)
+	}
+
+	return types
+}
+
+func TestEngineEnterDwellExit(t *testing.T) {
+	engine, err := NewEngine([]Geofence{home, park})
+	if err != nil {
+		t.Fatalf("NewEngine: %v", err)
+	}
+
+	base := time.Unix(1700000000, 0)
+
+	events := engine.Evaluate(newReport(base, -37.8136, 144.9631, 10))
+	if len(events) != 1 || events[0].Type != EventEnter || events[0].GeofenceId != "home" {
+		t.Fatalf("Evaluate: expected enter home, got %v", eventTypes(events))
+	}
+
+	// ~120m north of the centre but the accuracy circle still overlaps the fence
+	events = engine.Evaluate(newReport(base.Add(time.Minute), -37.8125, 144.9631, 50))
+	if len(events) != 0 {
+		t.Fatalf("Evaluate: expected noisy fix to keep state, got %v", eventTypes(events))
+	}
+
+	if !engine.IsInside("tracker-a", "home") {
+		t.Errorf("IsInside: expected tracker to still be home")
+	}
+
+	events = engine.Evaluate(newReport(base.Add(11*time.Minute), -37.8136, 144.9631, 10))
+	if len(events) != 1 || events[0].Type != EventDwell {
+		t.Fatalf("Evaluate: expected dwell, got %v", eventTypes(events))
+	}
+
+	events = engine.Evaluate(newReport(base.Add(20*time.Minute), -37.8250, 144.9750, 10))
+	if len(events) != 2 {
+		t.Fatalf("Evaluate: expected exit home and enter park, got %v", eventTypes(events))
+	}
+
+	if events[0].Type != EventExit || events[0].GeofenceId != "home" {
+		t.Errorf("Evaluate: expected exit home, got %s %s", events[0].Type, events[0].GeofenceId)
+	}
+
+	if events[1].Type != EventEnter || events[1].GeofenceId != "park" {
+		t.Errorf("Evaluate: expected enter park, got %s %s", events[1].Type, events[1].GeofenceId)
+	}
+}
+
+func TestEngineIgnoresStaleAndInaccurateReports(t *testing.T) {
+	engine, err := NewEngine([]Geofence{home}, WithMaxAccuracy(100))
+	if err != nil {
+		t.Fatalf("NewEngine: %v", err)
+	}
+
+	base := time.Unix(1700000000, 0)
+
+	events := engine.Evaluate(newReport(base, -37.8136, 144.9631, 500))
+	if len(events) != 0 {
+		t.Fatalf("Evaluate: expected inaccurate fix to be ignored, got %v", eventTypes(events))
+	}
+
+	engine.Evaluate(newReport(base, -37.8136, 144.9631, 10))
+
+	events = engine.Evaluate(newReport(base.Add(-time.Hour), -37.9, 145.1, 10))
+	if len(events) != 0 {
+		t.Fatalf("Evaluate: expected out of order fix to be ignored, got %v", eventTypes(events))
+	}
+}
+
+func TestNewEngineValidates(t *testing.T) {
+	_, err := NewEngine([]Geofence{{Id: "broken"}})
+	if err == nil {
+		t.Errorf("NewEngine: expected error for geofence without a shape")
+	}
+
+	_, err = NewEngine([]Geofence{home, home})
+	if err == nil {
+		t.Errorf("NewEngine: expected error for duplicate geofence ids")
+	}
+}
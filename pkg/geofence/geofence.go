@@ -0,0 +1,80 @@
+package geofence
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+var (
+	ErrMissingId    = errors.New("geofence id is empty")
+	ErrInvalidShape = errors.New("geofence must have either a radius or a polygon of at least 3 points")
+)
+
+type Point struct {
+	Latitude  float64 `json:"latitude" yaml:"latitude"`
+	Longitude float64 `json:"longitude" yaml:"longitude"`
+}
+
+// Geofence is either a circle (Latitude, Longitude and Radius in meters) or a
+// polygon. DwellSeconds, when set, emits a dwell event once a device has been
+// inside the geofence for that long.
+type Geofence struct {
+	Id   string `json:"id" yaml:"id"`
+	Name string `json:"name" yaml:"name"`
+
+	Latitude  float64 `json:"latitude,omitempty" yaml:"latitude,omitempty"`
+	Longitude float64 `json:"longitude,omitempty" yaml:"longitude,omitempty"`
+	Radius    float64 `json:"radius,omitempty" yaml:"radius,omitempty"`
+
+	Polygon []Point `json:"polygon,omitempty" yaml:"polygon,omitempty"`
+
+	DwellSeconds int `json:"dwellSeconds,omitempty" yaml:"dwellSeconds,omitempty"`
+}
+
+func (g *Geofence) Validate() error {
+	if g.Id == "" {
+		return ErrMissingId
+	}
+
+	isCircle := g.Radius > 0
+	isPolygon := len(g.Polygon) >= 3
+
+	if isCircle == isPolygon {
+		return fmt.Errorf("%w: %s", ErrInvalidShape, g.Id)
+	}
+
+	return nil
+}
+
+func (g *Geofence) GetName() string {
+	if g.Name == "" {
+		return g.Id
+	}
+
+	return g.Name
+}
+
+func (g *Geofence) Dwell() time.Duration {
+	return time.Duration(g.DwellSeconds) * time.Second
+}
+
+func (g *Geofence) Contains(p Point) bool {
+	if len(g.Polygon) >= 3 {
+		return pointInPolygon(p, g.Polygon)
+	}
+
+	return haversine(g.Latitude, g.Longitude, p.Latitude, p.Longitude) <= g.Radius
+}
+
+// BoundaryDistance returns the distance in meters from the point to the edge of the geofence
+func (g *Geofence) BoundaryDistance(p Point) float64 {
+	if len(g.Polygon) >= 3 {
+		return distanceToPolygonEdge(p, g.Polygon)
+	}
+
+	distance := haversine(g.Latitude, g.Longitude, p.Latitude, p.Longitude)
+
+	return math.Abs(distance - g.Radius)
+}
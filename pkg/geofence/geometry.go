@@ -0,0 +1,81 @@
+package geofence
+
+import "math"
+
+const earthRadiusMeters = 6371008.8
+
+func toRadians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+// haversine returns the great circle distance between two points in meters
+func haversine(lat1 float64, lon1 float64, lat2 float64, lon2 float64) float64 {
+	dLat := toRadians(lat2 - lat1)
+	dLon := toRadians(lon2 - lon1)
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return 2 * earthRadiusMeters * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// project maps a point onto a local plane centred on the origin, in meters.
+// Good enough for geofence sized areas away from the poles.
+func project(origin Point, p Point) (float64, float64) {
+	x := toRadians(p.Longitude-origin.Longitude) * math.Cos(toRadians(origin.Latitude)) * earthRadiusMeters
+	y := toRadians(p.Latitude-origin.Latitude) * earthRadiusMeters
+
+	return x, y
+}
+
+func pointInPolygon(p Point, polygon []Point) bool {
+	inside := false
+
+	j := len(polygon) - 1
+	for i := range polygon {
+		a, b := polygon[i], polygon[j]
+
+		crosses := (a.Latitude > p.Latitude) != (b.Latitude > p.Latitude)
+		if crosses {
+			intersect := (b.Longitude-a.Longitude)*(p.Latitude-a.Latitude)/(b.Latitude-a.Latitude) + a.Longitude
+			if p.Longitude < intersect {
+				inside = !inside
+			}
+		}
+
+		j = i
+	}
+
+	return inside
+}
+
+func distanceToPolygonEdge(p Point, polygon []Point) float64 {
+	minDistance := math.Inf(1)
+
+	j := len(polygon) - 1
+	for i := range polygon {
+		ax, ay := project(p, polygon[j])
+		bx, by := project(p, polygon[i])
+
+		minDistance = math.Min(minDistance, distanceToSegment(ax, ay, bx, by))
+
+		j = i
+	}
+
+	return minDistance
+}
+
+// distanceToSegment returns the distance from the origin to the segment a-b
+func distanceToSegment(ax float64, ay float64, bx float64, by float64) float64 {
+	dx, dy := bx-ax, by-ay
+
+	lengthSquared := dx*dx + dy*dy
+	if lengthSquared == 0 {
+		return math.Hypot(ax, ay)
+	}
+
+	t := -(ax*dx + ay*dy) / lengthSquared
+	t = math.Max(0, math.Min(1, t))
+
+	return math.Hypot(ax+t*dx, ay+t*dy)
+}
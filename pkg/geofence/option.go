@@ -0,0 +1,23 @@
+package geofence
+
+type Options struct {
+	maxAccuracy float64
+}
+
+func DefaultOptions() Options {
+	defaultOptions := Options{
+		maxAccuracy: 250,
+	}
+
+	return defaultOptions
+}
+
+type Option func(*Options)
+
+// WithMaxAccuracy ignores reports with an accuracy radius larger than the given
+// number of meters, zero disables the limit
+func WithMaxAccuracy(meters float64) Option {
+	return func(o *Options) {
+		o.maxAccuracy = meters
+	}
+}
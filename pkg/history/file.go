@@ -0,0 +1,232 @@
+package history
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	shared "github.com/dylanmazurek/go-findmy/pkg/shared/models"
+	"github.com/rs/zerolog/log"
+)
+
+// compactInterval is how often reports past the max age are dropped from a
+// running store
+const compactInterval = time.Hour
+
+// FileStore is an append-only JSON lines log of location reports. The log is
+// replayed into memory on open so queries never touch the disk. With a max
+// age, older reports are dropped and the log is rewritten without them.
+type FileStore struct {
+	index  *MemoryStore
+	path   string
+	maxAge time.Duration
+
+	mu          sync.Mutex
+	file        *os.File
+	compactedAt time.Time
+}
+
+func NewFileStore(ctx context.Context, path string, opts ...Option) (*FileStore, error) {
+	log := log.Ctx(ctx).With().Str("store", "history").Logger()
+
+	storeOptions := DefaultOptions()
+	for _, opt := range opts {
+		opt(&storeOptions)
+	}
+
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create history directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open history file: %w", err)
+	}
+
+	index := NewMemoryStore()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var count, skipped int
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var report shared.LocationReport
+		err := json.Unmarshal(line, &report)
+		if err != nil || report.UniqueId == nil {
+			skipped++
+			continue
+		}
+
+		index.insert(report)
+		count++
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("unable to read history file: %w", err)
+	}
+
+	if skipped > 0 {
+		log.Warn().Int("skipped", skipped).Msg("skipped unreadable history entries")
+	}
+
+	log.Debug().
+		Str("path", path).
+		Int("count", count).
+		Msg("history loaded")
+
+	newStore := &FileStore{
+		index:  index,
+		path:   path,
+		maxAge: storeOptions.maxAge,
+		file:   file,
+	}
+
+	if newStore.maxAge > 0 {
+		err = newStore.compact(ctx)
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+
+	return newStore, nil
+}
+
+func (f *FileStore) Append(ctx context.Context, reports ...shared.LocationReport) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return ErrStoreClosed
+	}
+
+	var lines []byte
+	for _, report := range reports {
+		if report.UniqueId == nil {
+			return ErrMissingUniqueId
+		}
+
+		line, err := json.Marshal(report)
+		if err != nil {
+			return err
+		}
+
+		lines = append(lines, line...)
+		lines = append(lines, '\n')
+	}
+
+	_, err := f.file.Write(lines)
+	if err != nil {
+		return fmt.Errorf("unable to write history: %w", err)
+	}
+
+	err = f.index.Append(ctx, reports...)
+	if err != nil {
+		return err
+	}
+
+	if f.maxAge > 0 && time.Since(f.compactedAt) >= compactInterval {
+		return f.compact(ctx)
+	}
+
+	return nil
+}
+
+// compact drops the reports older than the max age, rewriting the log when
+// any were dropped. The caller must hold mu, or own the store.
+func (f *FileStore) compact(ctx context.Context) error {
+	log := log.Ctx(ctx).With().Str("store", "history").Logger()
+
+	f.compactedAt = time.Now()
+
+	pruned := f.index.prune(f.compactedAt.Add(-f.maxAge))
+	if pruned == 0 {
+		return nil
+	}
+
+	tmpPath := f.path + ".tmp"
+	tmpFile, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("unable to compact history: %w", err)
+	}
+
+	writer := bufio.NewWriter(tmpFile)
+	encoder := json.NewEncoder(writer)
+	for _, report := range f.index.all() {
+		err = encoder.Encode(report)
+		if err != nil {
+			break
+		}
+	}
+
+	if err == nil {
+		err = writer.Flush()
+	}
+
+	if err == nil {
+		err = tmpFile.Sync()
+	}
+
+	closeErr := tmpFile.Close()
+	if err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Rename(tmpPath, f.path)
+	}
+
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("unable to compact history: %w", err)
+	}
+
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("unable to open history file: %w", err)
+	}
+
+	f.file.Close()
+	f.file = file
+
+	log.Debug().Int("pruned", pruned).Msg("history compacted")
+
+	return nil
+}
+
+func (f *FileStore) Query(ctx context.Context, uniqueId string, from time.Time, to time.Time) ([]shared.LocationReport, error) {
+	return f.index.Query(ctx, uniqueId, from, to)
+}
+
+func (f *FileStore) Latest(ctx context.Context) (map[string]shared.LocationReport, error) {
+	return f.index.Latest(ctx)
+}
+
+func (f *FileStore) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+
+	err := f.file.Close()
+	f.file = nil
+
+	f.index.Close()
+
+	return err
+}
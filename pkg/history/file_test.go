@@ -0,0 +1,169 @@
+package history
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	shared "github.com/dylanmazurek/go-findmy/pkg/shared/models"
+)
+
+func newReport(uniqueId string, reportTime time.Time, status shared.ReportStatus) shared.LocationReport {
+	return shared.LocationReport{
+		UniqueId:   &uniqueId,
+		ReportType: shared.ReportTypeLocation,
+		ReportTime: reportTime,
+		Status:     status,
+		Latitude:   -37.8136,
+		Longitude:  144.9631,
+		Accuracy:   12,
+	}
+}
+
+func TestFileStoreReplay(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "history", "reports.jsonl")
+
+	store, err := NewFileStore(ctx, path)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+
+	base := time.Unix(1700000000, 0).UTC()
+	err = store.Append(ctx,
+		newReport("tracker-a", base.Add(2*time.Minute), shared.ReportStatusCrowdsourced),
+		newReport("tracker-a", base, shared.ReportStatusLastKnown),
+		newReport("tracker-b", base.Add(time.Minute), shared.ReportStatusAggregated),
+	)
+	if err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+
+	err = store.Close()
+	if err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	store, err = NewFileStore(ctx, path)
+	if err != nil {
+		t.Fatalf("NewFileStore (reopen): %v", err)
+	}
+	defer store.Close()
+
+	reports, err := store.Query(ctx, "tracker-a", time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+
+	if len(reports) != 2 {
+		t.Fatalf("Query: expected 2 reports, got %d", len(reports))
+	}
+
+	if !reports[0].ReportTime.Equal(base) || reports[0].Status != shared.ReportStatusLastKnown {
+		t.Errorf("Query: expected oldest last known report first, got %v %v", reports[0].ReportTime, reports[0].Status)
+	}
+
+	reports, err = store.Query(ctx, "tracker-a", base.Add(time.Minute), time.Time{})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+
+	if len(reports) != 1 || reports[0].Status != shared.ReportStatusCrowdsourced {
+		t.Errorf("Query with from: expected only the crowdsourced report, got %d reports", len(reports))
+	}
+
+	latest, err := store.Latest(ctx)
+	if err != nil {
+		t.Fatalf("Latest: %v", err)
+	}
+
+	if len(latest) != 2 {
+		t.Fatalf("Latest: expected 2 devices, got %d", len(latest))
+	}
+
+	if !latest["tracker-a"].ReportTime.Equal(base.Add(2 * time.Minute)) {
+		t.Errorf("Latest: expected newest tracker-a report, got %v", latest["tracker-a"].ReportTime)
+	}
+}
+
+func TestFileStoreRejectsMissingUniqueId(t *testing.T) {
+	ctx := context.Background()
+
+	store, err := NewFileStore(ctx, filepath.Join(t.TempDir(), "reports.jsonl"))
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	defer store.Close()
+
+	err = store.Append(ctx, shared.LocationReport{})
+	if err != ErrMissingUniqueId {
+		t.Errorf("Append: expected ErrMissingUniqueId, got %v", err)
+	}
+}
+
+func TestFileStoreMaxAge(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "reports.jsonl")
+
+	store, err := NewFileStore(ctx, path)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+
+	now := time.Now().UTC()
+	err = store.Append(ctx,
+		newReport("tracker-a", now.Add(-48*time.Hour), shared.ReportStatusCrowdsourced),
+		newReport("tracker-a", now.Add(-time.Hour), shared.ReportStatusCrowdsourced),
+		newReport("tracker-b", now.Add(-72*time.Hour), shared.ReportStatusLastKnown),
+	)
+	if err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+
+	store.Close()
+
+	store, err = NewFileStore(ctx, path, WithMaxAge(24*time.Hour))
+	if err != nil {
+		t.Fatalf("NewFileStore (reopen): %v", err)
+	}
+	defer store.Close()
+
+	latest, err := store.Latest(ctx)
+	if err != nil {
+		t.Fatalf("Latest: %v", err)
+	}
+
+	if _, ok := latest["tracker-b"]; ok || len(latest) != 1 {
+		t.Fatalf("expected only tracker-a to be kept, got %v", latest)
+	}
+
+	reports, err := store.Query(ctx, "tracker-a", time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+
+	if len(reports) != 1 {
+		t.Fatalf("expected 1 report within the max age, got %d", len(reports))
+	}
+
+	// the next append compacts again once the interval has passed
+	store.compactedAt = now.Add(-2 * compactInterval)
+
+	err = store.Append(ctx, newReport("tracker-c", now.Add(-30*time.Hour), shared.ReportStatusLastKnown))
+	if err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Count(string(contents), "\n")
+	if lines != 1 {
+		t.Fatalf("expected the compacted log to hold 1 report, got %d", lines)
+	}
+}
@@ -0,0 +1,28 @@
+package history
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	shared "github.com/dylanmazurek/go-findmy/pkg/shared/models"
+)
+
+var (
+	ErrMissingUniqueId = errors.New("location report has no unique id")
+	ErrStoreClosed     = errors.New("history store is closed")
+)
+
+type Store interface {
+	// Append persists the given reports, reports without a unique id are rejected
+	Append(ctx context.Context, reports ...shared.LocationReport) error
+
+	// Query returns the reports for a device with a report time in [from, to],
+	// ordered by report time. A zero from or to leaves that end unbounded.
+	Query(ctx context.Context, uniqueId string, from time.Time, to time.Time) ([]shared.LocationReport, error)
+
+	// Latest returns the most recent report for every known device, keyed by unique id
+	Latest(ctx context.Context) (map[string]shared.LocationReport, error)
+
+	Close() error
+}
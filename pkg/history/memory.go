@@ -0,0 +1,154 @@
+package history
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	shared "github.com/dylanmazurek/go-findmy/pkg/shared/models"
+)
+
+type MemoryStore struct {
+	mu      sync.RWMutex
+	reports map[string][]shared.LocationReport
+	closed  bool
+}
+
+func NewMemoryStore() *MemoryStore {
+	newStore := &MemoryStore{
+		reports: make(map[string][]shared.LocationReport),
+	}
+
+	return newStore
+}
+
+func (m *MemoryStore) Append(ctx context.Context, reports ...shared.LocationReport) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return ErrStoreClosed
+	}
+
+	for _, report := range reports {
+		if report.UniqueId == nil {
+			return ErrMissingUniqueId
+		}
+	}
+
+	for _, report := range reports {
+		m.insert(report)
+	}
+
+	return nil
+}
+
+func (m *MemoryStore) insert(report shared.LocationReport) {
+	uniqueId := *report.UniqueId
+	deviceReports := m.reports[uniqueId]
+
+	idx, _ := slices.BinarySearchFunc(deviceReports, report.ReportTime, func(r shared.LocationReport, t time.Time) int {
+		return r.ReportTime.Compare(t)
+	})
+
+	for idx < len(deviceReports) && !deviceReports[idx].ReportTime.After(report.ReportTime) {
+		idx++
+	}
+
+	m.reports[uniqueId] = slices.Insert(deviceReports, idx, report)
+}
+
+// prune removes the reports before cutoff, returning how many were removed
+func (m *MemoryStore) prune(cutoff time.Time) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var pruned int
+	for uniqueId, deviceReports := range m.reports {
+		idx, _ := slices.BinarySearchFunc(deviceReports, cutoff, func(r shared.LocationReport, t time.Time) int {
+			return r.ReportTime.Compare(t)
+		})
+
+		if idx == 0 {
+			continue
+		}
+
+		pruned += idx
+
+		if idx == len(deviceReports) {
+			delete(m.reports, uniqueId)
+			continue
+		}
+
+		m.reports[uniqueId] = slices.Clone(deviceReports[idx:])
+	}
+
+	return pruned
+}
+
+// all returns every report, ordered by report time within each device
+func (m *MemoryStore) all() []shared.LocationReport {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var reports []shared.LocationReport
+	for _, deviceReports := range m.reports {
+		reports = append(reports, deviceReports...)
+	}
+
+	return reports
+}
+
+func (m *MemoryStore) Query(ctx context.Context, uniqueId string, from time.Time, to time.Time) ([]shared.LocationReport, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.closed {
+		return nil, ErrStoreClosed
+	}
+
+	var reports []shared.LocationReport
+	for _, report := range m.reports[uniqueId] {
+		if !from.IsZero() && report.ReportTime.Before(from) {
+			continue
+		}
+
+		if !to.IsZero() && report.ReportTime.After(to) {
+			break
+		}
+
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
+
+func (m *MemoryStore) Latest(ctx context.Context) (map[string]shared.LocationReport, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.closed {
+		return nil, ErrStoreClosed
+	}
+
+	latest := make(map[string]shared.LocationReport, len(m.reports))
+	for uniqueId, deviceReports := range m.reports {
+		if len(deviceReports) == 0 {
+			continue
+		}
+
+		latest[uniqueId] = deviceReports[len(deviceReports)-1]
+	}
+
+	return latest, nil
+}
+
+func (m *MemoryStore) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.closed = true
+
+	return nil
+}
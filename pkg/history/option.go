@@ -0,0 +1,23 @@
+package history
+
+import "time"
+
+type Options struct {
+	maxAge time.Duration
+}
+
+func DefaultOptions() Options {
+	defaultOptions := Options{}
+
+	return defaultOptions
+}
+
+type Option func(*Options)
+
+// WithMaxAge drops reports older than maxAge when the store is opened, and
+// then at most once per compaction interval, keeping every report when zero
+func WithMaxAge(maxAge time.Duration) Option {
+	return func(o *Options) {
+		o.maxAge = maxAge
+	}
+}
@@ -3,14 +3,19 @@ package notifier
 import (
 	"context"
 	"encoding/base64"
-	"os"
 	"slices"
+	"sync"
+	"time"
 
+	"github.com/cenkalti/backoff/v4"
 	"github.com/dylanmazurek/go-findmy/internal/publisher"
-	"github.com/dylanmazurek/go-findmy/internal/publisher/models"
 	"github.com/dylanmazurek/go-findmy/pkg/decryptor"
+	"github.com/dylanmazurek/go-findmy/pkg/dedup"
+	"github.com/dylanmazurek/go-findmy/pkg/geofence"
+	"github.com/dylanmazurek/go-findmy/pkg/history"
 	"github.com/dylanmazurek/go-findmy/pkg/notifier/constants"
 	"github.com/dylanmazurek/go-findmy/pkg/nova/models/protos/bindings"
+	"github.com/dylanmazurek/go-findmy/pkg/selection"
 	shared "github.com/dylanmazurek/go-findmy/pkg/shared/models"
 
 	sharedconstants "github.com/dylanmazurek/go-findmy/pkg/shared/constants"
@@ -22,20 +27,51 @@ import (
 var semanticLocations []shared.SemanticLocation
 
 type Client struct {
-	internalClient *fcmreceiver.FCMClient
+	conn          connection
+	newConnection func(ctx context.Context, forceRegister bool) (connection, error)
+	mu            sync.Mutex
 
 	decryptor *decryptor.Decryptor
-	publisher *publisher.Client
-
-	session     *Session
-	publishMqtt bool
+	publisher publisher.Publisher
+	history   history.Store
+	dedup     *dedup.Deduplicator
+	geofences *geofence.Engine
+	listeners []ReportListener
+
+	selector    selection.Selector
+	published   map[string]shared.LocationReport
+	publishedMu sync.Mutex
+
+	session      *Session
+	sessionMu    sync.Mutex
+	sessionSaver SessionSaver
+
+	// received message ids are saved in batches rather than on every message
+	persistentIdsDirty bool
+	persistentIdsTimer *time.Timer
+
+	status              ConnectionStatus
+	statusMu            sync.Mutex
+	connectionListeners []ConnectionListener
+	newBackOff          func() backoff.BackOff
+	startOnce           sync.Once
+	done                chan struct{}
+
+	handlers   sync.WaitGroup
+	handlersMu sync.Mutex
+	closing    bool
 }
 
-func NewClient(ctx context.Context, s Session, p *publisher.Client, sl []shared.SemanticLocation) (*Client, error) {
+func NewClient(ctx context.Context, s Session, opts ...Option) (*Client, error) {
 	log := log.Ctx(ctx).With().Str("client", constants.CLIENT_NAME).Logger()
 
 	log.Trace().Msg("creating")
 
+	clientOptions := DefaultOptions()
+	for _, opt := range opts {
+		opt(&clientOptions)
+	}
+
 	newDecryptor, err := decryptor.NewDecryptor(s.OwnerKey)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to create decryptor")
@@ -43,33 +79,55 @@ func NewClient(ctx context.Context, s Session, p *publisher.Client, sl []shared.
 		return nil, err
 	}
 
-	publishMqttEnv, hasPublishMqtt := os.LookupEnv("PUBLISH_MQTT")
-	if !hasPublishMqtt {
-		publishMqttEnv = "false"
+	semanticLocations = clientOptions.semanticLocations
+
+	newNotifier := &Client{
+		decryptor: newDecryptor,
+		publisher: clientOptions.publisher,
+		history:   clientOptions.history,
+		dedup:     clientOptions.dedup,
+		geofences: clientOptions.geofences,
+		listeners: clientOptions.reportListeners,
+
+		selector:  clientOptions.selector,
+		published: map[string]shared.LocationReport{},
+
+		session:      &s,
+		sessionSaver: clientOptions.sessionSaver,
+
+		status:              ConnectionStatus{State: ConnectionStopped, Since: time.Now()},
+		connectionListeners: clientOptions.connectionListeners,
+		newBackOff:          clientOptions.newBackOff,
+		done:                make(chan struct{}),
 	}
 
-	semanticLocations = sl
+	newNotifier.newConnection = newNotifier.newFcmConnection
 
 	log.Trace().Msg("initializing internal client")
-	newInternalClient, err := newInternalClient(ctx, s, true)
+	newConnection, err := newNotifier.newConnection(ctx, false)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to create internal client")
 		return nil, err
 	}
 
-	newNotifier := &Client{
-		decryptor: newDecryptor,
-		publisher: p,
+	newNotifier.setConnection(newConnection)
 
-		session:        &s,
-		internalClient: newInternalClient,
-		publishMqtt:    (publishMqttEnv == "true"),
+	return newNotifier, nil
+}
+
+// newFcmConnection creates a connection over a new FCM client
+func (n *Client) newFcmConnection(ctx context.Context, forceRegister bool) (connection, error) {
+	internalClient, err := n.newInternalClient(ctx, forceRegister)
+	if err != nil {
+		return nil, err
 	}
 
-	return newNotifier, nil
+	return &fcmConnection{internalClient: internalClient}, nil
 }
 
-func newInternalClient(ctx context.Context, session Session, forceRegister bool) (*fcmreceiver.FCMClient, error) {
+// newInternalClient creates an FCM client, reusing the registration stored in
+// the session unless there is none or forceRegister is set
+func (n *Client) newInternalClient(ctx context.Context, forceRegister bool) (*fcmreceiver.FCMClient, error) {
 	log := log.Ctx(ctx).With().Str("client", "fcm").Logger()
 
 	log.Trace().Msg("creating new fcm client")
@@ -84,6 +142,11 @@ func newInternalClient(ctx context.Context, session Session, forceRegister bool)
 		},
 	}
 
+	n.sessionMu.Lock()
+	defer n.sessionMu.Unlock()
+
+	session := n.session
+
 	err := session.prepareKeys(ctx, newClient)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to prepare keys")
@@ -91,12 +154,15 @@ func newInternalClient(ctx context.Context, session Session, forceRegister bool)
 		return nil, err
 	}
 
-	if !forceRegister {
-		log.Debug().Msg("using existing registration")
+	if !forceRegister && session.hasRegistration() {
+		log.Debug().
+			Int("persistent_ids", len(session.FcmSession.PersistentIds)).
+			Msg("using existing registration")
 
 		newClient.FcmToken = *session.FcmSession.RegistrationToken
 		newClient.AndroidId = *session.AndroidId
 		newClient.SecurityToken = *session.SecurityToken
+		newClient.PersistentIds = slices.Clone(session.FcmSession.PersistentIds)
 
 		return newClient, nil
 	}
@@ -113,43 +179,27 @@ func newInternalClient(ctx context.Context, session Session, forceRegister bool)
 	session.AndroidId = &androidId
 	session.SecurityToken = &securityToken
 
+	// persistent ids belong to the previous registration
+	session.FcmSession.PersistentIds = nil
+	n.persistentIdsDirty = false
+
 	log.Trace().Msg("registered fcm client")
 
+	n.saveSession(ctx)
+
 	return newClient, nil
 }
 
+// StartListening connects to FCM in the background, reconnecting until ctx
+// is cancelled
 func (n *Client) StartListening(ctx context.Context) error {
 	log := log.Ctx(ctx).With().Str("client", constants.CLIENT_NAME).Logger()
 
-	n.internalClient.OnDataMessage = func(message []byte) {
-		n.onDataMessage(ctx, message)
-	}
-
-	n.internalClient.OnRawMessage = func(message *fcmreceiver.DataMessageStanza) {
-		n.OnRawMessage(ctx, message)
-	}
-
-	go func() {
+	n.startOnce.Do(func() {
 		log.Debug().Msg("starting fcm client")
 
-		for {
-			err := n.internalClient.StartListening()
-			if err != nil {
-				log.Error().Err(err).Msg("fcm start listening failed")
-			}
-
-			log.Debug().Msg("restarting fcm client")
-
-			// Recreate the client to reset the connection
-			newClient, err := newInternalClient(ctx, *n.session, true)
-			if err != nil {
-				log.Error().Err(err).Msg("failed to recreate fcm client")
-				return
-			}
-
-			n.internalClient = newClient
-		}
-	}()
+		go n.supervise(ctx)
+	})
 
 	return nil
 }
@@ -165,6 +215,15 @@ func (n *Client) OnRawMessage(ctx context.Context, message *fcmreceiver.DataMess
 
 	log.Trace().Msg("received raw message")
 
+	if !n.beginHandling() {
+		log.Debug().Msg("ignoring message received while stopping")
+		return
+	}
+	defer n.handlers.Done()
+
+	n.messageReceived(ctx)
+	n.recordPersistentId(ctx, message.GetPersistentId())
+
 	appData := message.GetAppData()
 
 	fcmPayloadIdx := slices.IndexFunc(appData, func(i *fcmreceiver.AppData) bool {
@@ -209,7 +268,7 @@ func (n *Client) OnRawMessage(ctx context.Context, message *fcmreceiver.DataMess
 		return
 	}
 
-	var latestReport *shared.LocationReport
+	var reports []shared.LocationReport
 	for _, loc := range locations {
 		locationReport, err := n.handleReport(ctx, &deviceUpdate, loc)
 		if err != nil {
@@ -217,31 +276,44 @@ func (n *Client) OnRawMessage(ctx context.Context, message *fcmreceiver.DataMess
 			continue
 		}
 
-		if latestReport == nil || locationReport.ReportTime.After(latestReport.ReportTime) {
-			latestReport = locationReport
-		}
+		reports = append(reports, *locationReport)
 	}
 
-	if latestReport == nil {
-		log.Error().Msg("latest report is nil")
+	reports = n.filterReports(ctx, reports)
+	if len(reports) == 0 {
+		log.Debug().
+			Str(constants.LOG_USER_DEFINED_DEVICE_NAME, deviceUpdate.DeviceMetadata.GetUserDefinedDeviceName()).
+			Msg("no new reports in device update")
+
 		return
 	}
 
-	if n.publishMqtt {
-		pubReport := models.Report{
-			UniqueId:  *latestReport.UniqueId,
-			Latitude:  latestReport.Latitude,
-			Longitude: latestReport.Longitude,
-			Altitude:  latestReport.Altitude,
-			Accuracy:  latestReport.Accuracy,
-		}
+	slices.SortStableFunc(reports, func(a shared.LocationReport, b shared.LocationReport) int {
+		return a.ReportTime.Compare(b.ReportTime)
+	})
+
+	n.storeReports(ctx, reports)
+	n.evaluateGeofences(ctx, reports)
+	n.notifyListeners(ctx, reports)
+
+	selectedReport := n.selectReport(reports)
+	if selectedReport == nil {
+		log.Debug().
+			Str(constants.LOG_USER_DEFINED_DEVICE_NAME, deviceUpdate.DeviceMetadata.GetUserDefinedDeviceName()).
+			Msg("no report selected from device update")
 
-		_, err := n.publisher.UpdateTracker(ctx, pubReport)
+		return
+	}
+
+	selectedReport.ReportCount = len(locations)
+
+	if n.publisher != nil {
+		err := n.publisher.PublishReport(ctx, *selectedReport)
 		if err != nil {
 			log.Error().Err(err).Msg("failed to publish update")
 		}
 
-		log.Debug().Str("unique_id", pubReport.UniqueId).Msg("published location update")
+		log.Debug().Str("unique_id", *selectedReport.UniqueId).Msg("published location update")
 	}
 
 	deviceName := deviceUpdate.DeviceMetadata.GetUserDefinedDeviceName()
@@ -249,16 +321,193 @@ func (n *Client) OnRawMessage(ctx context.Context, message *fcmreceiver.DataMess
 	log.Info().
 		Str("name", deviceName).
 		Int("count", len(locations)).
-		Float64("latitude", latestReport.Latitude).
-		Float64("longitude", latestReport.Longitude).
-		Float64("accuracy", latestReport.Accuracy).
+		Float64("latitude", selectedReport.Latitude).
+		Float64("longitude", selectedReport.Longitude).
+		Float64("accuracy", selectedReport.Accuracy).
 		Msg("report")
 }
 
+// selectReport picks the report to publish with the selector, remembering it
+// as the previous report for the device
+func (n *Client) selectReport(reports []shared.LocationReport) *shared.LocationReport {
+	uniqueId := reports[0].UniqueId
+	if uniqueId == nil {
+		return n.selector.Select(nil, reports)
+	}
+
+	n.publishedMu.Lock()
+	defer n.publishedMu.Unlock()
+
+	var previous *shared.LocationReport
+	if report, ok := n.published[*uniqueId]; ok {
+		previous = &report
+	}
+
+	selected := n.selector.Select(previous, reports)
+	if selected != nil {
+		n.published[*uniqueId] = *selected
+	}
+
+	return selected
+}
+
+func (n *Client) filterReports(ctx context.Context, reports []shared.LocationReport) []shared.LocationReport {
+	log := log.Ctx(ctx)
+
+	if n.dedup == nil {
+		return reports
+	}
+
+	newReports := n.dedup.Filter(reports)
+
+	log.Trace().
+		Int("received", len(reports)).
+		Int("new", len(newReports)).
+		Msg("deduplicated reports")
+
+	err := n.dedup.Save()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to save dedup state")
+	}
+
+	return newReports
+}
+
+func (n *Client) evaluateGeofences(ctx context.Context, reports []shared.LocationReport) {
+	log := log.Ctx(ctx)
+
+	if n.geofences == nil {
+		return
+	}
+
+	for _, report := range reports {
+		events := n.geofences.Evaluate(report)
+		for _, event := range events {
+			log.Info().
+				Str("unique_id", event.UniqueId).
+				Str("geofence", event.GeofenceId).
+				Str("event", string(event.Type)).
+				Msg("geofence event")
+
+			geofencePublisher, ok := n.publisher.(publisher.GeofencePublisher)
+			if !ok {
+				continue
+			}
+
+			err := geofencePublisher.PublishGeofenceEvent(ctx, event)
+			if err != nil {
+				log.Error().Err(err).Msg("failed to publish geofence event")
+			}
+		}
+	}
+}
+
+func (n *Client) notifyListeners(ctx context.Context, reports []shared.LocationReport) {
+	for _, report := range reports {
+		for _, listener := range n.listeners {
+			listener(ctx, report)
+		}
+	}
+}
+
+func (n *Client) storeReports(ctx context.Context, reports []shared.LocationReport) {
+	log := log.Ctx(ctx)
+
+	if n.history == nil || len(reports) == 0 {
+		return
+	}
+
+	err := n.history.Append(ctx, reports...)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to store reports")
+		return
+	}
+
+	log.Trace().Int("count", len(reports)).Msg("stored reports")
+}
+
+// recordPersistentId stores the id of a received message in the session so it
+// is acknowledged on the next login, even after a restart. The session is
+// saved at most once per PERSISTENT_IDS_SAVE_INTERVAL.
+func (n *Client) recordPersistentId(ctx context.Context, persistentId string) {
+	n.sessionMu.Lock()
+	defer n.sessionMu.Unlock()
+
+	if !n.session.addPersistentId(persistentId) {
+		return
+	}
+
+	n.persistentIdsDirty = true
+
+	if n.persistentIdsTimer == nil {
+		n.persistentIdsTimer = time.AfterFunc(constants.PERSISTENT_IDS_SAVE_INTERVAL, func() {
+			n.flushPersistentIds(context.WithoutCancel(ctx))
+		})
+	}
+}
+
+// flushPersistentIds saves the session if message ids were received since it
+// was last saved
+func (n *Client) flushPersistentIds(ctx context.Context) {
+	n.sessionMu.Lock()
+	defer n.sessionMu.Unlock()
+
+	if n.persistentIdsTimer != nil {
+		n.persistentIdsTimer.Stop()
+		n.persistentIdsTimer = nil
+	}
+
+	if !n.persistentIdsDirty {
+		return
+	}
+
+	n.persistentIdsDirty = false
+
+	n.saveSession(ctx)
+}
+
+// saveSession passes the session to the session saver, if one is set, so a
+// restart can reuse the current registration
+func (n *Client) saveSession(ctx context.Context) {
+	saveSession(ctx, n.sessionSaver, n.session)
+}
+
+// saveSession passes the session to the session saver, if one is set
+func saveSession(ctx context.Context, saver SessionSaver, session *Session) {
+	log := log.Ctx(ctx)
+
+	if saver == nil {
+		return
+	}
+
+	err := saver(ctx, session)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to save session")
+		return
+	}
+
+	log.Trace().Msg("saved session")
+}
+
+// SharedSession returns the client's session for use by other clients, guarded
+// by the same lock the client uses when registering with FCM
+func (n *Client) SharedSession() *SharedSession {
+	newSharedSession := &SharedSession{
+		mu:      &n.sessionMu,
+		session: n.session,
+		save:    n.saveSession,
+	}
+
+	return newSharedSession
+}
+
 func (n *Client) GetFcmToken() *string {
-	if n.internalClient != nil {
-		return &n.internalClient.FcmToken
+	n.sessionMu.Lock()
+	defer n.sessionMu.Unlock()
+
+	if n.session.FcmSession == nil {
+		return nil
 	}
 
-	return nil
+	return n.session.FcmSession.RegistrationToken
 }
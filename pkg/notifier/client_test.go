@@ -0,0 +1,38 @@
+package notifier
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dylanmazurek/go-findmy/pkg/selection"
+	shared "github.com/dylanmazurek/go-findmy/pkg/shared/models"
+)
+
+func TestSelectReportIgnoresOlderReports(t *testing.T) {
+	n := &Client{
+		selector:  selection.Latest{},
+		published: map[string]shared.LocationReport{},
+	}
+
+	uniqueId := "tracker"
+	now := time.Now()
+
+	newReport := func(age time.Duration) shared.LocationReport {
+		return shared.LocationReport{UniqueId: &uniqueId, ReportTime: now.Add(-age)}
+	}
+
+	selected := n.selectReport([]shared.LocationReport{newReport(time.Minute)})
+	if selected == nil {
+		t.Fatal("expected the first report to be selected")
+	}
+
+	selected = n.selectReport([]shared.LocationReport{newReport(time.Hour)})
+	if selected != nil {
+		t.Fatalf("expected an older report to be ignored, got %v", selected.ReportTime)
+	}
+
+	selected = n.selectReport([]shared.LocationReport{newReport(0)})
+	if selected == nil || !selected.ReportTime.Equal(now) {
+		t.Fatal("expected a newer report to be selected")
+	}
+}
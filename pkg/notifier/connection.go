@@ -0,0 +1,308 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/dylanmazurek/go-findmy/pkg/notifier/constants"
+	fcmreceiver "github.com/morhaviv/go-fcm-receiver"
+	"github.com/rs/zerolog/log"
+)
+
+type ConnectionState string
+
+const (
+	ConnectionStopped    ConnectionState = "stopped"
+	ConnectionConnecting ConnectionState = "connecting"
+	ConnectionConnected  ConnectionState = "connected"
+	ConnectionBackoff    ConnectionState = "backoff"
+)
+
+// ConnectionStatus is a snapshot of the FCM connection
+type ConnectionStatus struct {
+	State         ConnectionState `json:"state"`
+	Since         time.Time       `json:"since"`
+	LastError     string          `json:"last_error,omitempty"`
+	Reconnects    int             `json:"reconnects"`
+	Messages      int             `json:"messages"`
+	LastMessageAt *time.Time      `json:"last_message_at,omitempty"`
+}
+
+// ConnectionListener is called whenever the FCM connection changes state
+type ConnectionListener func(ctx context.Context, status ConnectionStatus)
+
+// connection is a single session with FCM
+type connection interface {
+	// Listen blocks until the connection ends, passing received messages to
+	// the handlers
+	Listen(onData func(message []byte), onRaw func(message *fcmreceiver.DataMessageStanza)) error
+
+	// Close ends the connection, it is safe to call at any time and more than
+	// once
+	Close()
+}
+
+var errConnectionStopped = errors.New("connection stopped")
+
+// errClosedByServer is a connection the server ended after it was dialed and
+// the login sent, which is how FCM rejects stale credentials
+var errClosedByServer = errors.New("connection closed by server")
+
+// ConnectionStatus returns the current state of the FCM connection
+func (n *Client) ConnectionStatus() ConnectionStatus {
+	n.statusMu.Lock()
+	defer n.statusMu.Unlock()
+
+	return n.status
+}
+
+// Done is closed once the listener has stopped after its context is
+// cancelled, and every message already received has been handled
+func (n *Client) Done() <-chan struct{} {
+	return n.done
+}
+
+// supervise keeps the FCM client connected until ctx is cancelled, waiting
+// with exponential backoff between attempts
+func (n *Client) supervise(ctx context.Context) {
+	log := log.Ctx(ctx).With().Str("client", constants.CLIENT_NAME).Logger()
+
+	// the session is still saved once the service context is cancelled
+	saveCtx := context.WithoutCancel(ctx)
+
+	defer close(n.done)
+	defer n.flushPersistentIds(saveCtx)
+	defer n.drainHandlers()
+	defer n.setState(ctx, ConnectionStopped, nil)
+
+	reconnectBackOff := backoff.WithContext(n.newBackOff(), ctx)
+
+	rejections := 0
+	forceRegister := false
+	for {
+		conn, err := n.connecting(ctx, forceRegister)
+		if err == nil {
+			forceRegister = false
+
+			connectedAt := time.Now()
+
+			err = n.listen(ctx, conn)
+			if errors.Is(err, errConnectionStopped) {
+				return
+			}
+
+			// the receiver doesn't expose the login response, so a connection
+			// the server closes straight away is taken as rejected credentials.
+			// Dial and transport errors only back off.
+			switch {
+			case time.Since(connectedAt) >= constants.LOGIN_REJECTED_WINDOW:
+				rejections = 0
+				reconnectBackOff.Reset()
+			case errors.Is(err, errClosedByServer):
+				rejections++
+			}
+
+			if rejections >= constants.MAX_LOGIN_REJECTIONS {
+				log.Warn().Int("attempts", rejections).Msg("fcm login rejected, registering again")
+
+				forceRegister = true
+				rejections = 0
+			}
+
+			n.setConnection(nil)
+			n.flushPersistentIds(saveCtx)
+		}
+
+		if err == nil {
+			err = errors.New("connection closed")
+		}
+
+		log.Error().Err(err).Msg("fcm connection lost")
+
+		wait := reconnectBackOff.NextBackOff()
+		if wait == backoff.Stop {
+			return
+		}
+
+		n.setState(ctx, ConnectionBackoff, err)
+
+		log.Debug().Dur("wait", wait).Msg("reconnecting fcm client")
+
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return
+		}
+
+		n.statusMu.Lock()
+		n.status.Reconnects++
+		n.statusMu.Unlock()
+	}
+}
+
+// connecting returns the current FCM connection, creating a new one if the
+// previous connection was lost
+func (n *Client) connecting(ctx context.Context, forceRegister bool) (connection, error) {
+	n.setState(ctx, ConnectionConnecting, nil)
+
+	n.mu.Lock()
+	conn := n.conn
+	n.mu.Unlock()
+
+	if conn != nil && !forceRegister {
+		return conn, nil
+	}
+
+	conn, err := n.newConnection(ctx, forceRegister)
+	if err != nil {
+		return nil, err
+	}
+
+	n.setConnection(conn)
+
+	return conn, nil
+}
+
+// listen blocks until the connection is closed, or ctx is cancelled in which
+// case errConnectionStopped is returned
+func (n *Client) listen(ctx context.Context, conn connection) error {
+	log := log.Ctx(ctx)
+
+	// messages already received are handled to completion when stopping
+	handlerCtx := context.WithoutCancel(ctx)
+
+	onData := func(message []byte) {
+		n.onDataMessage(handlerCtx, message)
+	}
+
+	onRaw := func(message *fcmreceiver.DataMessageStanza) {
+		n.OnRawMessage(handlerCtx, message)
+	}
+
+	// a connection that stays open past the rejection window has logged in
+	connectedTimer := time.AfterFunc(constants.LOGIN_REJECTED_WINDOW, func() {
+		n.setState(ctx, ConnectionConnected, nil)
+	})
+	defer connectedTimer.Stop()
+
+	listenErr := make(chan error, 1)
+	go func() {
+		listenErr <- connectionError(conn.Listen(onData, onRaw))
+	}()
+
+	select {
+	case err := <-listenErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Debug().Msg("closing fcm client")
+
+	conn.Close()
+
+	select {
+	case <-listenErr:
+	case <-time.After(constants.CLOSE_TIMEOUT):
+		log.Warn().Msg("timed out waiting for fcm client to close")
+	}
+
+	return errConnectionStopped
+}
+
+// connectionError wraps the errors where the server ended the connection with
+// errClosedByServer. The receiver only returns string errors, a close tag or
+// EOF on read are from the server, anything else failed to dial or send.
+func connectionError(err error) error {
+	if err == nil {
+		return errors.New("connection closed")
+	}
+
+	message := err.Error()
+
+	closeTag := strings.Contains(message, "server returned close tag")
+	readEof := strings.HasPrefix(message, "failed to read from the FCM socket") && strings.HasSuffix(message, "EOF")
+	if closeTag || readEof {
+		return fmt.Errorf("%w: %s", errClosedByServer, message)
+	}
+
+	return err
+}
+
+// beginHandling registers an in-flight message, returning false once the
+// client is stopping
+func (n *Client) beginHandling() bool {
+	n.handlersMu.Lock()
+	defer n.handlersMu.Unlock()
+
+	if n.closing {
+		return false
+	}
+
+	n.handlers.Add(1)
+
+	return true
+}
+
+// drainHandlers stops new messages being handled and waits for those already
+// in flight
+func (n *Client) drainHandlers() {
+	n.handlersMu.Lock()
+	n.closing = true
+	n.handlersMu.Unlock()
+
+	n.handlers.Wait()
+}
+
+func (n *Client) setConnection(conn connection) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.conn = conn
+}
+
+// setState records a state change and notifies the connection listeners
+func (n *Client) setState(ctx context.Context, state ConnectionState, err error) {
+	log := log.Ctx(ctx)
+
+	n.statusMu.Lock()
+
+	if n.status.State == state && err == nil {
+		n.statusMu.Unlock()
+		return
+	}
+
+	n.status.State = state
+	n.status.Since = time.Now()
+	if err != nil {
+		n.status.LastError = err.Error()
+	}
+
+	status := n.status
+	n.statusMu.Unlock()
+
+	log.Info().
+		Str("state", string(status.State)).
+		Int("reconnects", status.Reconnects).
+		Msg("fcm connection state changed")
+
+	for _, listener := range n.connectionListeners {
+		listener(ctx, status)
+	}
+}
+
+// messageReceived updates the message counters, and marks the connection as
+// connected as messages are only delivered after logging in
+func (n *Client) messageReceived(ctx context.Context) {
+	now := time.Now()
+
+	n.statusMu.Lock()
+	n.status.Messages++
+	n.status.LastMessageAt = &now
+	n.statusMu.Unlock()
+
+	n.setState(ctx, ConnectionConnected, nil)
+}
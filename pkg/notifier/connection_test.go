@@ -0,0 +1,264 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	fcmreceiver "github.com/morhaviv/go-fcm-receiver"
+)
+
+func TestSetStateNotifiesListeners(t *testing.T) {
+	ctx := context.Background()
+
+	var states []ConnectionState
+	n := &Client{
+		status: ConnectionStatus{State: ConnectionStopped},
+		connectionListeners: []ConnectionListener{
+			func(ctx context.Context, status ConnectionStatus) {
+				states = append(states, status.State)
+			},
+		},
+	}
+
+	n.setState(ctx, ConnectionConnecting, nil)
+	n.setState(ctx, ConnectionConnecting, nil)
+	n.setState(ctx, ConnectionBackoff, errors.New("connection reset"))
+	n.messageReceived(ctx)
+
+	expected := []ConnectionState{ConnectionConnecting, ConnectionBackoff, ConnectionConnected}
+	if len(states) != len(expected) {
+		t.Fatalf("expected states %v, got %v", expected, states)
+	}
+
+	for i := range expected {
+		if states[i] != expected[i] {
+			t.Fatalf("expected states %v, got %v", expected, states)
+		}
+	}
+
+	status := n.ConnectionStatus()
+	if status.LastError != "connection reset" {
+		t.Fatalf("expected last error to be kept, got %q", status.LastError)
+	}
+
+	if status.Messages != 1 || status.LastMessageAt == nil {
+		t.Fatalf("expected message to be counted, got %+v", status)
+	}
+}
+
+func TestConnectionError(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		closedByServer bool
+	}{
+		{"dial", errors.New("failed to connect to the FCM server: dial tcp: lookup mtalk.google.com: no such host"), false},
+		{"handshake", errors.New("failed to send a handshake to the FCM server: broken pipe"), false},
+		{"reset", errors.New("failed to read from the FCM socket: read tcp: connection reset by peer"), false},
+		{"close tag", errors.New("server returned close tag"), true},
+		{"eof", errors.New("failed to read from the FCM socket: EOF"), true},
+		{"nil", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := connectionError(tt.err)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+
+			if errors.Is(err, errClosedByServer) != tt.closedByServer {
+				t.Fatalf("expected closed by server %t, got %v", tt.closedByServer, err)
+			}
+		})
+	}
+}
+
+var (
+	errDial     = errors.New("failed to connect to the FCM server: dial tcp: lookup mtalk.google.com: no such host")
+	errCloseTag = errors.New("server returned close tag")
+)
+
+// attempt scripts one connection attempt, either failing to create the
+// connection or ending it with listenErr
+type attempt struct {
+	createErr error
+	listenErr error
+}
+
+// fakeConnection ends with its error straight away, or blocks until closed
+// when it has none
+type fakeConnection struct {
+	listenErr error
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func (c *fakeConnection) Listen(onData func(message []byte), onRaw func(message *fcmreceiver.DataMessageStanza)) error {
+	if c.listenErr != nil {
+		return c.listenErr
+	}
+
+	<-c.closed
+
+	return errors.New("close was manually called")
+}
+
+func (c *fakeConnection) Close() {
+	c.closeOnce.Do(func() {
+		close(c.closed)
+	})
+}
+
+// fakeConnector plays back the attempts, then hands out a connection that
+// stays open until it is closed
+type fakeConnector struct {
+	mu            sync.Mutex
+	attempts      []attempt
+	forceRegister []bool
+
+	last    *fakeConnection
+	waiting chan struct{}
+}
+
+func (f *fakeConnector) newConnection(ctx context.Context, forceRegister bool) (connection, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.forceRegister = append(f.forceRegister, forceRegister)
+
+	if len(f.attempts) > 0 {
+		next := f.attempts[0]
+		f.attempts = f.attempts[1:]
+
+		if next.createErr != nil {
+			return nil, next.createErr
+		}
+
+		return &fakeConnection{listenErr: next.listenErr, closed: make(chan struct{})}, nil
+	}
+
+	f.last = &fakeConnection{closed: make(chan struct{})}
+	close(f.waiting)
+
+	return f.last, nil
+}
+
+func TestSupervise(t *testing.T) {
+	tests := []struct {
+		name          string
+		attempts      []attempt
+		forceRegister []bool
+	}{
+		{
+			name:          "stays connected",
+			forceRegister: []bool{false},
+		},
+		{
+			name:          "reconnects after server close",
+			attempts:      []attempt{{listenErr: errCloseTag}},
+			forceRegister: []bool{false, false},
+		},
+		{
+			name:          "retries failed connection",
+			attempts:      []attempt{{createErr: errors.New("register failed")}, {createErr: errors.New("register failed")}},
+			forceRegister: []bool{false, false, false},
+		},
+		{
+			name: "registers again after rejected logins",
+			attempts: []attempt{
+				{listenErr: errCloseTag},
+				{listenErr: errCloseTag},
+				{listenErr: errCloseTag},
+			},
+			forceRegister: []bool{false, false, false, true},
+		},
+		{
+			name: "dial failures never register again",
+			attempts: []attempt{
+				{listenErr: errDial},
+				{listenErr: errDial},
+				{listenErr: errDial},
+				{listenErr: errDial},
+			},
+			forceRegister: []bool{false, false, false, false, false},
+		},
+		{
+			name: "dial failures between rejected logins",
+			attempts: []attempt{
+				{listenErr: errCloseTag},
+				{listenErr: errDial},
+				{listenErr: errCloseTag},
+				{listenErr: errCloseTag},
+			},
+			forceRegister: []bool{false, false, false, false, true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			connector := &fakeConnector{
+				attempts: tt.attempts,
+				waiting:  make(chan struct{}),
+			}
+
+			n := &Client{
+				newConnection: connector.newConnection,
+				newBackOff: func() backoff.BackOff {
+					return backoff.NewConstantBackOff(time.Millisecond)
+				},
+				status: ConnectionStatus{State: ConnectionStopped},
+				done:   make(chan struct{}),
+			}
+
+			n.StartListening(ctx)
+
+			select {
+			case <-connector.waiting:
+			case <-time.After(5 * time.Second):
+				t.Fatal("timed out waiting for the scripted attempts")
+			}
+
+			cancel()
+
+			select {
+			case <-n.Done():
+			case <-time.After(5 * time.Second):
+				t.Fatal("timed out waiting for supervise to stop")
+			}
+
+			select {
+			case <-connector.last.closed:
+			default:
+				t.Fatal("expected the open connection to be closed")
+			}
+
+			if len(connector.forceRegister) != len(tt.forceRegister) {
+				t.Fatalf("expected attempts %v, got %v", tt.forceRegister, connector.forceRegister)
+			}
+
+			for i := range tt.forceRegister {
+				if connector.forceRegister[i] != tt.forceRegister[i] {
+					t.Fatalf("expected attempts %v, got %v", tt.forceRegister, connector.forceRegister)
+				}
+			}
+
+			status := n.ConnectionStatus()
+			if status.State != ConnectionStopped {
+				t.Fatalf("expected stopped state, got %s", status.State)
+			}
+
+			if status.Reconnects != len(tt.attempts) {
+				t.Fatalf("expected %d reconnects, got %d", len(tt.attempts), status.Reconnects)
+			}
+		})
+	}
+}
@@ -1,5 +1,7 @@
 package constants
 
+import "time"
+
 const (
 	CLIENT_NAME = "notifier"
 )
@@ -13,6 +15,26 @@ const (
 	GMAIL_DOMAIN             = "gmail.com"
 )
 
+const (
+	// MAX_PERSISTENT_IDS bounds the received message ids kept in the session
+	// and sent with the MCS login
+	MAX_PERSISTENT_IDS = 100
+
+	// a connection the server closes within LOGIN_REJECTED_WINDOW of being
+	// opened is counted as a rejected login, and MAX_LOGIN_REJECTIONS in a row cause
+	// the client to register again
+	LOGIN_REJECTED_WINDOW = 10 * time.Second
+	MAX_LOGIN_REJECTIONS  = 3
+
+	RECONNECT_INITIAL_INTERVAL = time.Second
+	RECONNECT_MAX_INTERVAL     = 5 * time.Minute
+	CLOSE_TIMEOUT              = 5 * time.Second
+
+	// received message ids are saved at most once per interval, and when the
+	// connection is lost
+	PERSISTENT_IDS_SAVE_INTERVAL = time.Minute
+)
+
 const (
 	LOG_USER_DEFINED_DEVICE_NAME = "device_name"
 )
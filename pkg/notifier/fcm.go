@@ -2,7 +2,11 @@ package notifier
 
 import (
 	"context"
+	"slices"
+	"sync"
+	"time"
 
+	"github.com/dylanmazurek/go-findmy/pkg/notifier/constants"
 	"github.com/dylanmazurek/go-findmy/pkg/notifier/models"
 	fcmreceiver "github.com/morhaviv/go-fcm-receiver"
 	"github.com/rs/zerolog/log"
@@ -32,3 +36,96 @@ func (s *Session) prepareKeys(ctx context.Context, c *fcmreceiver.FCMClient) err
 
 	return nil
 }
+
+// hasRegistration reports whether the session holds a complete FCM
+// registration that can be reused instead of registering again
+func (s *Session) hasRegistration() bool {
+	if s.AndroidId == nil || *s.AndroidId == 0 {
+		return false
+	}
+
+	if s.SecurityToken == nil || *s.SecurityToken == 0 {
+		return false
+	}
+
+	if s.FcmSession == nil || s.FcmSession.RegistrationToken == nil || *s.FcmSession.RegistrationToken == "" {
+		return false
+	}
+
+	return true
+}
+
+// addPersistentId records the id of a received message, so the next MCS
+// login acknowledges it and it is not delivered again. It returns false if
+// the id was already recorded
+func (s *Session) addPersistentId(persistentId string) bool {
+	if persistentId == "" || slices.Contains(s.FcmSession.PersistentIds, persistentId) {
+		return false
+	}
+
+	persistentIds := append(slices.Clone(s.FcmSession.PersistentIds), persistentId)
+	if len(persistentIds) > constants.MAX_PERSISTENT_IDS {
+		persistentIds = persistentIds[len(persistentIds)-constants.MAX_PERSISTENT_IDS:]
+	}
+
+	s.FcmSession.PersistentIds = persistentIds
+
+	return true
+}
+
+// fcmConnection is a connection over an FCM receiver client. The receiver
+// panics when closing a socket it hasn't finished opening, and has no hook
+// for when it has, so the connection only counts as started once it has
+// stayed open for LOGIN_REJECTED_WINDOW or delivered a message.
+type fcmConnection struct {
+	internalClient *fcmreceiver.FCMClient
+
+	mu      sync.Mutex
+	started bool
+	stopped bool
+}
+
+func (c *fcmConnection) Listen(onData func(message []byte), onRaw func(message *fcmreceiver.DataMessageStanza)) error {
+	c.internalClient.OnDataMessage = func(message []byte) {
+		c.setStarted()
+		onData(message)
+	}
+
+	c.internalClient.OnRawMessage = func(message *fcmreceiver.DataMessageStanza) {
+		c.setStarted()
+		onRaw(message)
+	}
+
+	startedTimer := time.AfterFunc(constants.LOGIN_REJECTED_WINDOW, c.setStarted)
+	defer startedTimer.Stop()
+
+	err := c.internalClient.StartListening()
+
+	c.mu.Lock()
+	c.stopped = true
+	c.mu.Unlock()
+
+	return err
+}
+
+// Close closes the socket, unless it was never started or has already been
+// closed
+func (c *fcmConnection) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.started || c.stopped {
+		return
+	}
+
+	c.stopped = true
+
+	c.internalClient.Close()
+}
+
+func (c *fcmConnection) setStarted() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.started = true
+}
@@ -0,0 +1,91 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/dylanmazurek/go-findmy/pkg/notifier/constants"
+	"github.com/dylanmazurek/go-findmy/pkg/notifier/models"
+)
+
+func TestAddPersistentId(t *testing.T) {
+	session := &Session{FcmSession: &models.FcmSession{}}
+
+	if !session.addPersistentId("0:1") {
+		t.Fatal("expected new id to be added")
+	}
+
+	if session.addPersistentId("0:1") {
+		t.Fatal("expected duplicate id to be ignored")
+	}
+
+	if session.addPersistentId("") {
+		t.Fatal("expected empty id to be ignored")
+	}
+
+	for i := 2; i <= constants.MAX_PERSISTENT_IDS+1; i++ {
+		session.addPersistentId(fmt.Sprintf("0:%d", i))
+	}
+
+	persistentIds := session.FcmSession.PersistentIds
+	if len(persistentIds) != constants.MAX_PERSISTENT_IDS {
+		t.Fatalf("expected %d ids, got %d", constants.MAX_PERSISTENT_IDS, len(persistentIds))
+	}
+
+	if persistentIds[0] != "0:2" {
+		t.Fatalf("expected oldest id to be dropped, got %s first", persistentIds[0])
+	}
+}
+
+func TestRecordPersistentIdBatchesSaves(t *testing.T) {
+	ctx := context.Background()
+
+	saves := 0
+	n := &Client{
+		session: &Session{FcmSession: &models.FcmSession{}},
+		sessionSaver: func(ctx context.Context, session *Session) error {
+			saves++
+			return nil
+		},
+	}
+
+	n.recordPersistentId(ctx, "0:1")
+	n.recordPersistentId(ctx, "0:2")
+
+	if saves != 0 {
+		t.Fatalf("expected no saves before flush, got %d", saves)
+	}
+
+	n.flushPersistentIds(ctx)
+	n.flushPersistentIds(ctx)
+
+	if saves != 1 {
+		t.Fatalf("expected 1 save, got %d", saves)
+	}
+
+	if n.persistentIdsTimer != nil {
+		t.Fatal("expected save timer to be stopped")
+	}
+}
+
+func TestHasRegistration(t *testing.T) {
+	androidId := uint64(1)
+	securityToken := uint64(2)
+	registrationToken := "fcm-token"
+
+	session := &Session{
+		AndroidId:     &androidId,
+		SecurityToken: &securityToken,
+		FcmSession:    &models.FcmSession{},
+	}
+
+	if session.hasRegistration() {
+		t.Fatal("expected registration without a token to be incomplete")
+	}
+
+	session.FcmSession.RegistrationToken = &registrationToken
+	if !session.hasRegistration() {
+		t.Fatal("expected registration to be complete")
+	}
+}
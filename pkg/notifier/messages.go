@@ -36,20 +36,16 @@ func (n *Client) handleReport(ctx context.Context, deviceUpdate *bindings.Device
 			return nil, err
 		}
 
+		if !newLocationReport.HasLocation() {
+			log.Warn().
+				Str("semantic_name", *newLocationReport.SemanticName).
+				Msg("unknown semantic location, publishing without coordinates")
+		}
+
 		return &newLocationReport, nil
 	}
 
 	newLocationReport.ReportType = shared.ReportTypeLocation
-	newLocationReport.Latitude = loc.Latitude
-	newLocationReport.Longitude = loc.Longitude
-	newLocationReport.Altitude = loc.Altitude
-
-	accuracy := deviceUpdate.GetDeviceMetadata().
-		GetInformation().GetLocationInformation().
-		GetReports().RecentLocationAndNetworkLocations.
-		GetRecentLocation().GetGeoLocation().GetAccuracy()
-
-	newLocationReport.Accuracy = float64(accuracy)
 
 	return &newLocationReport, nil
 }
@@ -66,19 +62,21 @@ func processSemanticLocation(locationReport *shared.LocationReport) error {
 		return hasName
 	})
 
-	if semanticLocationIdx == -1 {
-		err := fmt.Errorf("failed to find semantic location")
+	locationReport.ReportType = shared.ReportTypeSemantic
+	locationReport.Latitude = 0
+	locationReport.Longitude = 0
+	locationReport.Accuracy = 0
+	locationReport.Altitude = 0
 
-		return err
+	// names missing from the semantic locations are kept without coordinates
+	if semanticLocationIdx == -1 {
+		return nil
 	}
 
 	semanticLocation := semanticLocations[semanticLocationIdx]
 
-	locationReport.ReportType = shared.ReportTypeSemantic
 	locationReport.Latitude = semanticLocation.Latitude
 	locationReport.Longitude = semanticLocation.Longitude
-	locationReport.Accuracy = 4
-	locationReport.Altitude = 0
 	locationReport.SemanticName = &semanticLocation.Names[0]
 
 	return nil
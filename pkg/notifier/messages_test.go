@@ -0,0 +1,44 @@
+package notifier
+
+import (
+	"testing"
+
+	shared "github.com/dylanmazurek/go-findmy/pkg/shared/models"
+)
+
+func TestProcessSemanticLocation(t *testing.T) {
+	semanticLocations = []shared.SemanticLocation{
+		{Names: []string{"Home", "Casa"}, Latitude: -33.86, Longitude: 151.21},
+	}
+	t.Cleanup(func() {
+		semanticLocations = nil
+	})
+
+	knownName := "Casa"
+	known := shared.LocationReport{SemanticName: &knownName, Accuracy: 4}
+
+	err := processSemanticLocation(&known)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !known.HasLocation() || *known.SemanticName != "Home" {
+		t.Fatalf("expected known location to get its coordinates and name, got %+v", known)
+	}
+
+	if known.Accuracy != 0 {
+		t.Fatalf("expected no accuracy for semantic location, got %f", known.Accuracy)
+	}
+
+	unknownName := "Office"
+	unknown := shared.LocationReport{SemanticName: &unknownName}
+
+	err = processSemanticLocation(&unknown)
+	if err != nil {
+		t.Fatalf("expected unknown location to be kept, got %v", err)
+	}
+
+	if unknown.HasLocation() || *unknown.SemanticName != "Office" {
+		t.Fatalf("expected unknown location to keep its name without coordinates, got %+v", unknown)
+	}
+}
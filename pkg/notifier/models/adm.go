@@ -1,5 +1,12 @@
 package models
 
+import "time"
+
 type AdmSession struct {
 	AasToken string `json:"aasToken"`
+
+	// AuthToken is the last ADM token issued for the AAS token, kept so a
+	// restart can reuse it until it expires
+	AuthToken     string     `json:"authToken,omitempty"`
+	AuthExpiresAt *time.Time `json:"authExpiresAt,omitempty"`
 }
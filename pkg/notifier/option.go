@@ -0,0 +1,122 @@
+package notifier
+
+import (
+	"context"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/dylanmazurek/go-findmy/internal/publisher"
+	"github.com/dylanmazurek/go-findmy/pkg/dedup"
+	"github.com/dylanmazurek/go-findmy/pkg/geofence"
+	"github.com/dylanmazurek/go-findmy/pkg/history"
+	"github.com/dylanmazurek/go-findmy/pkg/notifier/constants"
+	"github.com/dylanmazurek/go-findmy/pkg/selection"
+	shared "github.com/dylanmazurek/go-findmy/pkg/shared/models"
+)
+
+type ReportListener func(ctx context.Context, report shared.LocationReport)
+
+type Options struct {
+	publisher         publisher.Publisher
+	history           history.Store
+	dedup             *dedup.Deduplicator
+	geofences         *geofence.Engine
+	selector          selection.Selector
+	semanticLocations []shared.SemanticLocation
+	reportListeners   []ReportListener
+	sessionSaver      SessionSaver
+
+	connectionListeners []ConnectionListener
+	newBackOff          func() backoff.BackOff
+}
+
+func DefaultOptions() Options {
+	defaultOptions := Options{
+		selector: selection.Latest{},
+		newBackOff: func() backoff.BackOff {
+			return newReconnectBackOff(constants.RECONNECT_INITIAL_INTERVAL, constants.RECONNECT_MAX_INTERVAL)
+		},
+	}
+
+	return defaultOptions
+}
+
+type Option func(*Options)
+
+func WithPublisher(p publisher.Publisher) Option {
+	return func(o *Options) {
+		o.publisher = p
+	}
+}
+
+func WithHistory(h history.Store) Option {
+	return func(o *Options) {
+		o.history = h
+	}
+}
+
+func WithDeduplicator(d *dedup.Deduplicator) Option {
+	return func(o *Options) {
+		o.dedup = d
+	}
+}
+
+func WithGeofences(g *geofence.Engine) Option {
+	return func(o *Options) {
+		o.geofences = g
+	}
+}
+
+// WithSelector picks which of the new reports in a device update is
+// published, the newest by default
+func WithSelector(s selection.Selector) Option {
+	return func(o *Options) {
+		o.selector = s
+	}
+}
+
+func WithSemanticLocations(sl []shared.SemanticLocation) Option {
+	return func(o *Options) {
+		o.semanticLocations = sl
+	}
+}
+
+// WithReportListener is called with every new decrypted report, in report time order
+func WithReportListener(l ReportListener) Option {
+	return func(o *Options) {
+		o.reportListeners = append(o.reportListeners, l)
+	}
+}
+
+// WithSessionSaver is called whenever the FCM registration changes
+func WithSessionSaver(s SessionSaver) Option {
+	return func(o *Options) {
+		o.sessionSaver = s
+	}
+}
+
+// WithConnectionListener is called whenever the FCM connection changes state
+func WithConnectionListener(l ConnectionListener) Option {
+	return func(o *Options) {
+		o.connectionListeners = append(o.connectionListeners, l)
+	}
+}
+
+// WithReconnectInterval sets the first and longest wait between attempts to
+// reconnect to FCM
+func WithReconnectInterval(initialInterval time.Duration, maxInterval time.Duration) Option {
+	return func(o *Options) {
+		o.newBackOff = func() backoff.BackOff {
+			return newReconnectBackOff(initialInterval, maxInterval)
+		}
+	}
+}
+
+// newReconnectBackOff retries forever, with the default jitter of 50%
+func newReconnectBackOff(initialInterval time.Duration, maxInterval time.Duration) backoff.BackOff {
+	return backoff.NewExponentialBackOff(
+		backoff.WithInitialInterval(initialInterval),
+		backoff.WithMaxInterval(maxInterval),
+		backoff.WithMaxElapsedTime(0),
+	)
+}
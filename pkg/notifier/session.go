@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/dylanmazurek/go-findmy/pkg/notifier/constants"
 	"github.com/dylanmazurek/go-findmy/pkg/notifier/models"
@@ -22,6 +23,10 @@ type Session struct {
 	AdmSession    *models.AdmSession `json:"admSession"`
 }
 
+// SessionSaver persists a session after it has been changed by a client, such
+// as after a new FCM registration or a refreshed ADM token
+type SessionSaver func(ctx context.Context, session *Session) error
+
 func (s *Session) GetEmail() string {
 	email := fmt.Sprintf("%s@%s", s.Username, constants.GMAIL_DOMAIN)
 
@@ -94,8 +99,31 @@ func (s *Session) SaveSession(ctx context.Context, f string) error {
 		return err
 	}
 
-	jsonDetailsBytes := []byte(jsonDetails)
-	err = os.WriteFile(f, jsonDetailsBytes, 0644)
+	// write to a temporary file and rename it so a crash never leaves a
+	// partially written session behind
+	tmpFile, err := os.CreateTemp(filepath.Dir(f), ".session-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+
+	_, err = tmpFile.Write(jsonDetails)
+	if err != nil {
+		tmpFile.Close()
+		return err
+	}
+
+	err = tmpFile.Close()
+	if err != nil {
+		return err
+	}
+
+	err = os.Chmod(tmpFile.Name(), 0600)
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(tmpFile.Name(), f)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,102 @@
+package notifier
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// SharedSession gives other clients, such as the nova client, access to a
+// session without racing the notifier client that updates it
+type SharedSession struct {
+	mu      *sync.Mutex
+	session *Session
+	save    func(ctx context.Context)
+}
+
+// NewSharedSession shares a session that is not used by a notifier client
+func NewSharedSession(session *Session, saver SessionSaver) *SharedSession {
+	newSharedSession := &SharedSession{
+		mu:      &sync.Mutex{},
+		session: session,
+		save: func(ctx context.Context) {
+			saveSession(ctx, saver, session)
+		},
+	}
+
+	return newSharedSession
+}
+
+func (s *SharedSession) GetEmail() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.session.GetEmail()
+}
+
+func (s *SharedSession) AndroidId() *uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.session.AndroidId == nil {
+		return nil
+	}
+
+	androidId := *s.session.AndroidId
+
+	return &androidId
+}
+
+func (s *SharedSession) RegistrationToken() *string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.session.FcmSession == nil || s.session.FcmSession.RegistrationToken == nil {
+		return nil
+	}
+
+	registrationToken := *s.session.FcmSession.RegistrationToken
+
+	return &registrationToken
+}
+
+func (s *SharedSession) AasToken() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.session.AdmSession == nil {
+		return ""
+	}
+
+	return s.session.AdmSession.AasToken
+}
+
+// AdmAuth returns the stored ADM token and when it expires
+func (s *SharedSession) AdmAuth() (string, *time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	admSession := s.session.AdmSession
+	if admSession == nil || admSession.AuthExpiresAt == nil {
+		return "", nil
+	}
+
+	expiresAt := *admSession.AuthExpiresAt
+
+	return admSession.AuthToken, &expiresAt
+}
+
+// UpdateAdmAuth stores a refreshed ADM token and saves the session
+func (s *SharedSession) UpdateAdmAuth(ctx context.Context, token string, expiresAt time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.session.AdmSession == nil {
+		return
+	}
+
+	s.session.AdmSession.AuthToken = token
+	s.session.AdmSession.AuthExpiresAt = &expiresAt
+
+	s.save(ctx)
+}
@@ -0,0 +1,59 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dylanmazurek/go-findmy/pkg/notifier/models"
+)
+
+// TestSharedSessionConcurrentWrites runs the notifier and a shared session
+// writer together, run with -race to catch unguarded access
+func TestSharedSessionConcurrentWrites(t *testing.T) {
+	ctx := context.Background()
+
+	n := &Client{
+		session: &Session{
+			FcmSession: &models.FcmSession{},
+			AdmSession: &models.AdmSession{},
+		},
+		sessionSaver: func(ctx context.Context, session *Session) error {
+			_, err := json.Marshal(session)
+			return err
+		},
+	}
+
+	sharedSession := n.SharedSession()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+
+		for i := range 100 {
+			n.recordPersistentId(ctx, fmt.Sprintf("0:%d", i))
+			n.flushPersistentIds(ctx)
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+
+		for i := range 100 {
+			sharedSession.UpdateAdmAuth(ctx, fmt.Sprintf("token-%d", i), time.Now())
+			sharedSession.RegistrationToken()
+		}
+	}()
+
+	wg.Wait()
+
+	token, expiresAt := sharedSession.AdmAuth()
+	if token != "token-99" || expiresAt == nil {
+		t.Fatalf("expected the last token to be stored, got %q", token)
+	}
+}
@@ -2,7 +2,9 @@ package nova
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/dylanmazurek/go-findmy/pkg/nova/constants"
@@ -11,28 +13,76 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-func (c *Client) ExecuteAction(ctx context.Context, canonicId string) error {
+func NewLocateTrackerAction() *bindings.ExecuteActionType {
+	lastHighTrafficEnablingTime := time.Now().Add(time.Duration(-5 * time.Hour)).Unix()
+
+	action := &bindings.ExecuteActionType{
+		LocateTracker: &bindings.ExecuteActionLocateTrackerType{
+			LastHighTrafficEnablingTime: &bindings.Time{
+				Seconds: uint32(lastHighTrafficEnablingTime),
+			},
+			ContributorType: bindings.SpotContributorType_FMDN_ALL_LOCATIONS,
+		},
+	}
+
+	return action
+}
+
+func NewStartSoundAction(component bindings.DeviceComponent) *bindings.ExecuteActionType {
+	action := &bindings.ExecuteActionType{
+		StartSound: &bindings.ExecuteActionSoundType{
+			Component: component,
+		},
+	}
+
+	return action
+}
+
+func NewStopSoundAction(component bindings.DeviceComponent) *bindings.ExecuteActionType {
+	action := &bindings.ExecuteActionType{
+		StopSound: &bindings.ExecuteActionSoundType{
+			Component: component,
+		},
+	}
+
+	return action
+}
+
+func (c *Client) LocateTracker(ctx context.Context, canonicId string) error {
+	return c.ExecuteAction(ctx, canonicId, NewLocateTrackerAction())
+}
+
+func (c *Client) PlaySound(ctx context.Context, canonicId string, component bindings.DeviceComponent) error {
+	return c.ExecuteAction(ctx, canonicId, NewStartSoundAction(component))
+}
+
+func (c *Client) StopSound(ctx context.Context, canonicId string, component bindings.DeviceComponent) error {
+	return c.ExecuteAction(ctx, canonicId, NewStopSoundAction(component))
+}
+
+func (c *Client) ExecuteAction(ctx context.Context, canonicId string, action *bindings.ExecuteActionType) error {
 	requestUuid := uuid.New()
 
 	log := log.Ctx(ctx).With().
 		Str(constants.LOG_CLIENT_UUID, c.clientUuid).
 		Str(constants.LOG_CANONIC_ID, canonicId).
 		Str(constants.LOG_REQUEST_UUID, requestUuid.String()).
+		Str(constants.LOG_ACTION_TYPE, actionType(action)).
 		Logger()
 
-	log.Trace().Msg("executing action")
+	if action == nil {
+		return ErrActionNil
+	}
 
-	lastHighTrafficEnablingTime := time.Now().Add(time.Duration(-5 * time.Hour)).Unix()
+	registrationToken := c.session.RegistrationToken()
+	if registrationToken == nil {
+		return ErrNotRegisteredForFcm
+	}
+
+	log.Trace().Msg("executing action")
 
 	var reqMessage = &bindings.ExecuteActionRequest{
-		Action: &bindings.ExecuteActionType{
-			LocateTracker: &bindings.ExecuteActionLocateTrackerType{
-				LastHighTrafficEnablingTime: &bindings.Time{
-					Seconds: uint32(lastHighTrafficEnablingTime),
-				},
-				ContributorType: bindings.SpotContributorType_FMDN_ALL_LOCATIONS,
-			},
-		},
+		Action: action,
 		Scope: &bindings.ExecuteActionScope{
 			Type: bindings.DeviceType_SPOT_DEVICE,
 			Device: &bindings.ExecuteActionDeviceIdentifier{
@@ -47,7 +97,7 @@ func (c *Client) ExecuteAction(ctx context.Context, canonicId string) error {
 			FmdClientUuid: c.clientUuid,
 			Unknown:       true,
 			GcmRegistrationId: &bindings.GcmCloudMessagingIdProtobuf{
-				Id: *c.notifierSession.FcmSession.RegistrationToken,
+				Id: *registrationToken,
 			},
 		},
 	}
@@ -59,8 +109,36 @@ func (c *Client) ExecuteAction(ctx context.Context, canonicId string) error {
 
 	err = c.Do(ctx, req, nil)
 	if err != nil {
-		return err
+		return fmt.Errorf("%w: %w", ErrFailedToExecuteAction, err)
 	}
 
 	return nil
 }
+
+func actionType(action *bindings.ExecuteActionType) string {
+	switch {
+	case action.GetLocateTracker() != nil:
+		return constants.ACTION_LOCATE
+	case action.GetStartSound() != nil:
+		return constants.ACTION_START_SOUND
+	case action.GetStopSound() != nil:
+		return constants.ACTION_STOP_SOUND
+	}
+
+	return "unknown"
+}
+
+func ParseDeviceComponent(component string) (bindings.DeviceComponent, error) {
+	switch strings.ToLower(strings.TrimSpace(component)) {
+	case "", "unspecified":
+		return bindings.DeviceComponent_DEVICE_COMPONENT_UNSPECIFIED, nil
+	case "left":
+		return bindings.DeviceComponent_DEVICE_COMPONENT_LEFT, nil
+	case "right":
+		return bindings.DeviceComponent_DEVICE_COMPONENT_RIGHT, nil
+	case "case":
+		return bindings.DeviceComponent_DEVICE_COMPONENT_CASE, nil
+	}
+
+	return bindings.DeviceComponent_DEVICE_COMPONENT_UNSPECIFIED, fmt.Errorf("unknown device component: %s", component)
+}
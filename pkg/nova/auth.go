@@ -103,12 +103,16 @@ func (c *Client) refreshAdmToken(ctx context.Context) error {
 
 	formData := url.Values{}
 	formData.Set("accountType", "HOSTED_OR_GOOGLE")
-	formData.Set("Email", c.notifierSession.GetEmail())
+	formData.Set("Email", c.session.GetEmail())
 	formData.Set("has_permission", "1")
-	formData.Set("EncryptedPasswd", c.notifierSession.AdmSession.AasToken)
+	formData.Set("EncryptedPasswd", c.session.AasToken())
 	formData.Set("service", scope)
 	formData.Set("source", constants.AUTH_CLIENT_SOURCE)
-	formData.Set("androidId", fmt.Sprintf("%d", c.notifierSession.AndroidId))
+	androidId := c.session.AndroidId()
+	if androidId != nil {
+		formData.Set("androidId", fmt.Sprintf("%d", *androidId))
+	}
+
 	formData.Set("app", shared.ADM_APP_ID)
 	formData.Set("client_sig", constants.AUTH_CLIENT_SIG)
 	formData.Set("device_country", "us")
@@ -169,5 +173,32 @@ func (c *Client) refreshAdmToken(ctx context.Context) error {
 
 	c.auth = &auth
 
+	c.saveAuth(ctx)
+
 	return nil
 }
+
+// sessionAuth returns the ADM token stored in the session, if it has not
+// expired
+func (c *Client) sessionAuth() *models.Auth {
+	token, expiresAt := c.session.AdmAuth()
+	if token == "" || expiresAt == nil {
+		return nil
+	}
+
+	auth := &models.Auth{
+		Token:     token,
+		ExpiresAt: models.UnixTime{Time: *expiresAt},
+	}
+
+	if !auth.IsValid() {
+		return nil
+	}
+
+	return auth
+}
+
+// saveAuth stores the current ADM token in the session, which saves it
+func (c *Client) saveAuth(ctx context.Context) {
+	c.session.UpdateAdmAuth(ctx, c.auth.Token, c.auth.ExpiresAt.Time)
+}
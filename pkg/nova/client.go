@@ -22,7 +22,7 @@ type Client struct {
 	clientUuid string
 	auth       *models.Auth
 
-	notifierSession *notifier.Session
+	session *notifier.SharedSession
 }
 
 func NewClient(ctx context.Context, opts ...Option) (*Client, error) {
@@ -44,9 +44,11 @@ func NewClient(ctx context.Context, opts ...Option) (*Client, error) {
 
 		clientUuid: newClientUuid.String(),
 
-		notifierSession: clientOptions.notifierSession,
+		session: clientOptions.session,
 	}
 
+	newClient.auth = newClient.sessionAuth()
+
 	err := newClient.validateAdmToken(ctx)
 	if err == ErrTokenExpired {
 		log.Debug().
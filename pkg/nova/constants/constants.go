@@ -24,11 +24,18 @@ const (
 	PATH_EXECUTE_ACTION = "nbe_execute_action"
 )
 
+const (
+	ACTION_LOCATE      = "locate"
+	ACTION_START_SOUND = "start_sound"
+	ACTION_STOP_SOUND  = "stop_sound"
+)
+
 const (
 	LOG_CLIENT_UUID  = "client_uuid"
 	LOG_CANONIC_ID   = "canonic_id"
 	LOG_DEVICE_ID    = "device_id"
 	LOG_REQUEST_UUID = "request_uuid"
+	LOG_ACTION_TYPE  = "action_type"
 
 	// HTTP request logging
 	LOG_HTTP_STATUS = "http_status"
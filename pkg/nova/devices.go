@@ -59,7 +59,7 @@ func (c *Client) RefreshDevices(ctx context.Context) error {
 			Str(constants.LOG_CANONIC_ID, *firstCanonicId).
 			Msg("executing action")
 
-		err = c.ExecuteAction(ctx, *firstCanonicId)
+		err = c.LocateTracker(ctx, *firstCanonicId)
 		if err != nil {
 			return err
 		}
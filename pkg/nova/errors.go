@@ -11,6 +11,12 @@ var (
 	ErrTokenExpired              = errors.New("token expired")
 )
 
+// actions
+var (
+	ErrActionNil           = errors.New("action is nil")
+	ErrNotRegisteredForFcm = errors.New("session is not registered for fcm")
+)
+
 // response
 var (
 	ErrResponseNotProtoMessage = errors.New("response is not a proto.message")
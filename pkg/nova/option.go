@@ -5,7 +5,7 @@ import (
 )
 
 type Options struct {
-	notifierSession *notifier.Session
+	session *notifier.SharedSession
 }
 
 func DefaultOptions() Options {
@@ -16,8 +16,10 @@ func DefaultOptions() Options {
 
 type Option func(*Options)
 
-func WithNotifierSession(s *notifier.Session) Option {
+// WithSession sets the session used to authenticate, refreshed ADM tokens are
+// saved through it
+func WithSession(s *notifier.SharedSession) Option {
 	return func(o *Options) {
-		o.notifierSession = s
+		o.session = s
 	}
 }
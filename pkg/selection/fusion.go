@@ -0,0 +1,58 @@
+package selection
+
+import (
+	"math"
+
+	shared "github.com/dylanmazurek/go-findmy/pkg/shared/models"
+)
+
+const earthRadiusMeters = 6371008.8
+
+// fuse returns the centroid of the reports weighted by the inverse of their
+// accuracy squared. The combined accuracy grows with the spread of the
+// reports, so reports that disagree give a larger radius.
+func fuse(reports []shared.LocationReport) shared.LocationReport {
+	var totalWeight, latitude, longitude, altitude float64
+	for _, report := range reports {
+		weight := 1 / (report.Accuracy * report.Accuracy)
+
+		totalWeight += weight
+		latitude += report.Latitude * weight
+		longitude += report.Longitude * weight
+		altitude += report.Altitude * weight
+	}
+
+	// the fused report takes the time and status of the newest report
+	latest := Latest{}.Select(nil, reports)
+
+	newReport := *latest
+	newReport.Latitude = latitude / totalWeight
+	newReport.Longitude = longitude / totalWeight
+	newReport.Altitude = altitude / totalWeight
+	newReport.PayloadHash = ""
+
+	var spread float64
+	for _, report := range reports {
+		d := distance(newReport, report)
+		spread += d * d / (report.Accuracy * report.Accuracy)
+	}
+
+	newReport.Accuracy = math.Sqrt((1 + spread) / totalWeight)
+
+	return newReport
+}
+
+// distance returns the great circle distance between two reports in meters
+func distance(a shared.LocationReport, b shared.LocationReport) float64 {
+	dLat := toRadians(b.Latitude - a.Latitude)
+	dLon := toRadians(b.Longitude - a.Longitude)
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(a.Latitude))*math.Cos(toRadians(b.Latitude))*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return 2 * earthRadiusMeters * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+func toRadians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
@@ -0,0 +1,35 @@
+package selection
+
+import "time"
+
+type Options struct {
+	window   time.Duration
+	maxSpeed float64
+}
+
+func DefaultOptions() Options {
+	defaultOptions := Options{
+		window: 10 * time.Minute,
+	}
+
+	return defaultOptions
+}
+
+type Option func(*Options)
+
+// WithWindow only considers reports within the given duration of the newest
+// report in an update, older reports are never preferred
+func WithWindow(window time.Duration) Option {
+	return func(o *Options) {
+		o.window = window
+	}
+}
+
+// WithMaxSpeed rejects reports that would mean the device moved faster than
+// the given meters per second since the last published report, zero disables
+// the check
+func WithMaxSpeed(metersPerSecond float64) Option {
+	return func(o *Options) {
+		o.maxSpeed = metersPerSecond
+	}
+}
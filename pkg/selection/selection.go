@@ -0,0 +1,278 @@
+package selection
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	shared "github.com/dylanmazurek/go-findmy/pkg/shared/models"
+)
+
+// Selector picks the report to publish from the new reports in a device
+// update. previous is the report last published for the device, or nil, and
+// reports older than it are never picked. Returning nil publishes nothing for
+// the update.
+type Selector interface {
+	Select(previous *shared.LocationReport, reports []shared.LocationReport) *shared.LocationReport
+}
+
+type Policy string
+
+const (
+	PolicyLatest    Policy = "latest"
+	PolicyAccurate  Policy = "accurate"
+	PolicyPreferred Policy = "preferred"
+	PolicyFused     Policy = "fused"
+)
+
+func ParsePolicy(policy string) (Policy, error) {
+	switch Policy(strings.ToLower(strings.TrimSpace(policy))) {
+	case PolicyLatest:
+		return PolicyLatest, nil
+	case PolicyAccurate:
+		return PolicyAccurate, nil
+	case PolicyPreferred:
+		return PolicyPreferred, nil
+	case PolicyFused:
+		return PolicyFused, nil
+	}
+
+	return "", fmt.Errorf("unknown selection policy: %s", policy)
+}
+
+// New returns the selector for a policy, rejecting implausible jumps first
+// when a max speed is set
+func New(policy Policy, opts ...Option) (Selector, error) {
+	selectorOptions := DefaultOptions()
+	for _, opt := range opts {
+		opt(&selectorOptions)
+	}
+
+	var selector Selector
+	switch policy {
+	case PolicyLatest:
+		selector = Latest{}
+	case PolicyAccurate:
+		selector = Accurate{Window: selectorOptions.window}
+	case PolicyPreferred:
+		selector = Preferred{Window: selectorOptions.window}
+	case PolicyFused:
+		selector = Fused{
+			Window: selectorOptions.window,
+			Next:   Preferred{Window: selectorOptions.window},
+		}
+	default:
+		return nil, fmt.Errorf("unknown selection policy: %s", policy)
+	}
+
+	if selectorOptions.maxSpeed > 0 {
+		selector = MaxSpeed{MetersPerSecond: selectorOptions.maxSpeed, Next: selector}
+	}
+
+	return selector, nil
+}
+
+// Latest picks the newest report
+type Latest struct{}
+
+func (Latest) Select(previous *shared.LocationReport, reports []shared.LocationReport) *shared.LocationReport {
+	reports = since(previous, reports)
+
+	var latest *shared.LocationReport
+	for i, report := range reports {
+		if latest == nil || report.ReportTime.After(latest.ReportTime) {
+			latest = &reports[i]
+		}
+	}
+
+	return latest
+}
+
+// Accurate picks the report with the smallest accuracy radius within Window
+// of the newest report, preferring newer reports on ties
+type Accurate struct {
+	Window time.Duration
+}
+
+func (a Accurate) Select(previous *shared.LocationReport, reports []shared.LocationReport) *shared.LocationReport {
+	candidates := recent(since(previous, reports), a.Window)
+
+	var best *shared.LocationReport
+	for i, report := range candidates {
+		if best == nil || moreAccurate(report, *best) {
+			best = &candidates[i]
+		}
+	}
+
+	return best
+}
+
+// Preferred picks the most accurate of the best ranked reports within Window
+// of the newest report, so the owner's own last known location wins over the
+// crowdsourced network, which wins over semantic placeholders
+type Preferred struct {
+	Window time.Duration
+}
+
+func (p Preferred) Select(previous *shared.LocationReport, reports []shared.LocationReport) *shared.LocationReport {
+	candidates := recent(since(previous, reports), p.Window)
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	bestRank := rank(candidates[0])
+	for _, report := range candidates {
+		bestRank = min(bestRank, rank(report))
+	}
+
+	candidates = slices.DeleteFunc(candidates, func(report shared.LocationReport) bool {
+		return rank(report) != bestRank
+	})
+
+	return Accurate{}.Select(previous, candidates)
+}
+
+// Fused combines the crowdsourced reports within Window of the newest report
+// into an accuracy weighted centroid, falling back to Next when there are
+// fewer than two or a better ranked report is available
+type Fused struct {
+	Window time.Duration
+	Next   Selector
+}
+
+func (f Fused) Select(previous *shared.LocationReport, reports []shared.LocationReport) *shared.LocationReport {
+	reports = since(previous, reports)
+
+	var crowdsourced []shared.LocationReport
+	for _, report := range recent(reports, f.Window) {
+		if !report.HasLocation() {
+			continue
+		}
+
+		if rank(report) < rankCrowdsourced {
+			return f.Next.Select(previous, reports)
+		}
+
+		if report.Status == shared.ReportStatusCrowdsourced && report.Accuracy > 0 {
+			crowdsourced = append(crowdsourced, report)
+		}
+	}
+
+	if len(crowdsourced) < 2 {
+		return f.Next.Select(previous, reports)
+	}
+
+	fused := fuse(crowdsourced)
+
+	return &fused
+}
+
+// MaxSpeed drops reports that would mean the device moved faster than
+// MetersPerSecond since the previous report, allowing for the accuracy of
+// both, then selects from the rest with Next
+type MaxSpeed struct {
+	MetersPerSecond float64
+	Next            Selector
+}
+
+func (m MaxSpeed) Select(previous *shared.LocationReport, reports []shared.LocationReport) *shared.LocationReport {
+	if previous == nil || !previous.HasLocation() {
+		return m.Next.Select(previous, reports)
+	}
+
+	plausible := slices.DeleteFunc(slices.Clone(reports), func(report shared.LocationReport) bool {
+		if !report.HasLocation() {
+			return false
+		}
+
+		elapsed := math.Max(math.Abs(report.ReportTime.Sub(previous.ReportTime).Seconds()), 1)
+
+		moved := distance(*previous, report) - previous.Accuracy - report.Accuracy
+
+		return moved/elapsed > m.MetersPerSecond
+	})
+
+	if len(plausible) == 0 {
+		return nil
+	}
+
+	return m.Next.Select(previous, plausible)
+}
+
+// since returns the reports no older than previous, so a device never moves
+// back to a location it was at before
+func since(previous *shared.LocationReport, reports []shared.LocationReport) []shared.LocationReport {
+	if previous == nil {
+		return reports
+	}
+
+	return slices.DeleteFunc(slices.Clone(reports), func(report shared.LocationReport) bool {
+		return report.ReportTime.Before(previous.ReportTime)
+	})
+}
+
+// recent returns the reports within window of the newest, or every report
+// when window is zero
+func recent(reports []shared.LocationReport, window time.Duration) []shared.LocationReport {
+	latest := Latest{}.Select(nil, reports)
+	if latest == nil || window <= 0 {
+		return slices.Clone(reports)
+	}
+
+	cutoff := latest.ReportTime.Add(-window)
+
+	return slices.DeleteFunc(slices.Clone(reports), func(report shared.LocationReport) bool {
+		return report.ReportTime.Before(cutoff)
+	})
+}
+
+const (
+	rankLastKnown = iota
+	rankAggregated
+	rankCrowdsourced
+	rankSemantic
+	rankNoLocation
+)
+
+// rank orders reports by how much they are trusted, lower is better
+func rank(report shared.LocationReport) int {
+	if !report.HasLocation() {
+		return rankNoLocation
+	}
+
+	if report.IsOwnReport {
+		return rankLastKnown
+	}
+
+	switch report.Status {
+	case shared.ReportStatusLastKnown:
+		return rankLastKnown
+	case shared.ReportStatusAggregated:
+		return rankAggregated
+	case shared.ReportStatusCrowdsourced:
+		return rankCrowdsourced
+	}
+
+	return rankSemantic
+}
+
+// moreAccurate reports whether a is more accurate than b, where reports
+// without coordinates or an accuracy are the least accurate
+func moreAccurate(a shared.LocationReport, b shared.LocationReport) bool {
+	accuracyA, accuracyB := radius(a), radius(b)
+	if accuracyA != accuracyB {
+		return accuracyA < accuracyB
+	}
+
+	return a.ReportTime.After(b.ReportTime)
+}
+
+func radius(report shared.LocationReport) float64 {
+	if !report.HasLocation() || report.Accuracy <= 0 {
+		return math.Inf(1)
+	}
+
+	return report.Accuracy
+}
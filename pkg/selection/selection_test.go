@@ -0,0 +1,204 @@
+package selection
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	shared "github.com/dylanmazurek/go-findmy/pkg/shared/models"
+)
+
+var base = time.Unix(1700000000, 0)
+
+func newReport(offset time.Duration, status shared.ReportStatus, latitude float64, longitude float64, accuracy float64) shared.LocationReport {
+	uniqueId := "tracker-a"
+
+	return shared.LocationReport{
+		UniqueId:   &uniqueId,
+		ReportType: shared.ReportTypeLocation,
+		ReportTime: base.Add(offset),
+		Status:     status,
+		Latitude:   latitude,
+		Longitude:  longitude,
+		Accuracy:   accuracy,
+	}
+}
+
+func newSemanticReport(offset time.Duration) shared.LocationReport {
+	semanticName := "Home"
+
+	return shared.LocationReport{
+		ReportType:   shared.ReportTypeSemantic,
+		ReportTime:   base.Add(offset),
+		Status:       shared.ReportStatusSemantic,
+		SemanticName: &semanticName,
+	}
+}
+
+func TestLatest(t *testing.T) {
+	reports := []shared.LocationReport{
+		newReport(0, shared.ReportStatusLastKnown, -37.8136, 144.9631, 10),
+		newSemanticReport(time.Minute),
+	}
+
+	selected := Latest{}.Select(nil, reports)
+	if selected == nil || selected.ReportType != shared.ReportTypeSemantic {
+		t.Fatalf("expected the newest report, got %+v", selected)
+	}
+
+	if (Latest{}).Select(nil, nil) != nil {
+		t.Fatal("expected no report from an empty update")
+	}
+}
+
+func TestAccurate(t *testing.T) {
+	reports := []shared.LocationReport{
+		newReport(-time.Hour, shared.ReportStatusCrowdsourced, -37.8136, 144.9631, 5),
+		newReport(0, shared.ReportStatusCrowdsourced, -37.8136, 144.9631, 80),
+		newReport(time.Minute, shared.ReportStatusCrowdsourced, -37.8136, 144.9631, 20),
+		newReport(2*time.Minute, shared.ReportStatusCrowdsourced, -37.8136, 144.9631, 0),
+		newSemanticReport(3 * time.Minute),
+	}
+
+	selected := Accurate{Window: 10 * time.Minute}.Select(nil, reports)
+	if selected == nil || selected.Accuracy != 20 {
+		t.Fatalf("expected the most accurate recent report, got %+v", selected)
+	}
+}
+
+func TestPreferred(t *testing.T) {
+	reports := []shared.LocationReport{
+		newReport(0, shared.ReportStatusCrowdsourced, -37.8136, 144.9631, 5),
+		newReport(time.Minute, shared.ReportStatusLastKnown, -37.8140, 144.9635, 40),
+		newReport(2*time.Minute, shared.ReportStatusLastKnown, -37.8140, 144.9635, 30),
+		newSemanticReport(3 * time.Minute),
+	}
+
+	selected := Preferred{Window: 10 * time.Minute}.Select(nil, reports)
+	if selected == nil || selected.Status != shared.ReportStatusLastKnown || selected.Accuracy != 30 {
+		t.Fatalf("expected the most accurate own report, got %+v", selected)
+	}
+
+	selected = Preferred{Window: 10 * time.Minute}.Select(nil, []shared.LocationReport{newSemanticReport(0)})
+	if selected == nil || selected.ReportType != shared.ReportTypeSemantic {
+		t.Fatalf("expected a semantic report when there is nothing better, got %+v", selected)
+	}
+}
+
+func TestFused(t *testing.T) {
+	fused := Fused{Window: 10 * time.Minute, Next: Latest{}}
+
+	reports := []shared.LocationReport{
+		newReport(0, shared.ReportStatusCrowdsourced, -37.8136, 144.9631, 10),
+		newReport(time.Minute, shared.ReportStatusCrowdsourced, -37.8138, 144.9631, 10),
+		newReport(2*time.Minute, shared.ReportStatusCrowdsourced, -37.9000, 144.9631, 1000),
+	}
+
+	selected := fused.Select(nil, reports)
+	if selected == nil {
+		t.Fatal("expected a fused report")
+	}
+
+	if math.Abs(selected.Latitude-(-37.8137)) > 0.0002 {
+		t.Errorf("expected the centroid to follow the accurate reports, got %f", selected.Latitude)
+	}
+
+	if selected.Accuracy <= 0 || selected.Accuracy >= 100 {
+		t.Errorf("expected a combined accuracy below the inaccurate report, got %f", selected.Accuracy)
+	}
+
+	if !selected.ReportTime.Equal(base.Add(2 * time.Minute)) {
+		t.Errorf("expected the fused report to take the newest time, got %s", selected.ReportTime)
+	}
+
+	agreeing := fused.Select(nil, []shared.LocationReport{
+		newReport(0, shared.ReportStatusCrowdsourced, -37.8136, 144.9631, 10),
+		newReport(time.Minute, shared.ReportStatusCrowdsourced, -37.8136, 144.9631, 10),
+	})
+	if agreeing.Accuracy >= 10 {
+		t.Errorf("expected agreeing reports to combine to a smaller radius, got %f", agreeing.Accuracy)
+	}
+
+	withOwn := append(reports, newReport(3*time.Minute, shared.ReportStatusLastKnown, -37.8140, 144.9635, 50))
+	selected = fused.Select(nil, withOwn)
+	if selected.Status != shared.ReportStatusLastKnown {
+		t.Fatalf("expected an own report to be used over fusing, got %+v", selected)
+	}
+}
+
+func TestMaxSpeed(t *testing.T) {
+	previous := newReport(-time.Hour, shared.ReportStatusLastKnown, -37.8136, 144.9631, 10)
+
+	// 30 m/s is 108 km/h
+	maxSpeed := MaxSpeed{MetersPerSecond: 30, Next: Latest{}}
+
+	nearby := newReport(0, shared.ReportStatusCrowdsourced, -37.8500, 144.9631, 20)
+	jump := newReport(time.Minute, shared.ReportStatusCrowdsourced, -33.8688, 151.2093, 20)
+
+	selected := maxSpeed.Select(&previous, []shared.LocationReport{nearby, jump})
+	if selected == nil || selected.Latitude != nearby.Latitude {
+		t.Fatalf("expected the implausible jump to be rejected, got %+v", selected)
+	}
+
+	selected = maxSpeed.Select(&previous, []shared.LocationReport{jump})
+	if selected != nil {
+		t.Fatalf("expected nothing to be selected, got %+v", selected)
+	}
+
+	selected = maxSpeed.Select(nil, []shared.LocationReport{jump})
+	if selected == nil {
+		t.Fatal("expected any report to be plausible without a previous report")
+	}
+}
+
+func TestSelectIgnoresReportsBeforePrevious(t *testing.T) {
+	previous := newReport(time.Minute, shared.ReportStatusCrowdsourced, -37.8136, 144.9631, 20)
+
+	tests := []struct {
+		name     string
+		selector Selector
+	}{
+		{name: "latest", selector: Latest{}},
+		{name: "accurate", selector: Accurate{Window: 10 * time.Minute}},
+		{name: "preferred", selector: Preferred{Window: 10 * time.Minute}},
+		{name: "fused", selector: Fused{Window: 10 * time.Minute, Next: Preferred{Window: 10 * time.Minute}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reports := []shared.LocationReport{
+				newReport(0, shared.ReportStatusLastKnown, -37.8136, 144.9631, 5),
+				newReport(2*time.Minute, shared.ReportStatusCrowdsourced, -37.8140, 144.9635, 50),
+			}
+
+			selected := tt.selector.Select(&previous, reports)
+			if selected == nil || !selected.ReportTime.Equal(base.Add(2*time.Minute)) {
+				t.Fatalf("expected the report after previous, got %+v", selected)
+			}
+
+			selected = tt.selector.Select(&previous, reports[:1])
+			if selected != nil {
+				t.Fatalf("expected no report older than previous, got %+v", selected)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	for _, policy := range []string{"latest", "accurate", "Preferred", " fused "} {
+		parsed, err := ParsePolicy(policy)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = New(parsed, WithMaxSpeed(50))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, err := ParsePolicy("newest")
+	if err == nil {
+		t.Fatal("expected an unknown policy to fail")
+	}
+}
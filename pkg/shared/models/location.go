@@ -6,18 +6,32 @@ import (
 )
 
 type LocationReport struct {
-	UniqueId *string
+	UniqueId *string `json:"unique_id"`
 
-	ReportType ReportType
-	ReportTime time.Time
+	ReportType ReportType   `json:"report_type"`
+	ReportTime time.Time    `json:"report_time"`
+	Status     ReportStatus `json:"status"`
 
-	Latitude  float64
-	Longitude float64
-	Altitude  float64
-	Accuracy  float64
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Altitude  float64 `json:"altitude"`
+	Accuracy  float64 `json:"accuracy"`
+
+	// Beacon time counter the report was encrypted with
+	DeviceTimeOffset uint32 `json:"device_time_offset,omitempty"`
+
+	// Whether the report came from the owner's own devices rather than the
+	// crowdsourced network
+	IsOwnReport bool `json:"is_own_report,omitempty"`
 
 	// Semantic location name
-	SemanticName *string
+	SemanticName *string `json:"semantic_name,omitempty"`
+
+	// Hash of the encrypted payload the report was decrypted from
+	PayloadHash string `json:"payload_hash,omitempty"`
+
+	// Number of reports in the device update the report was received in
+	ReportCount int `json:"report_count,omitempty"`
 }
 
 type ReportType int8
@@ -38,6 +52,78 @@ func (r *ReportType) String() string {
 	}
 }
 
+func (r ReportType) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+func (r *ReportType) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "semantic":
+		*r = ReportTypeSemantic
+	case "location":
+		*r = ReportTypeLocation
+	default:
+		return fmt.Errorf("unknown report type: %s", text)
+	}
+
+	return nil
+}
+
+type ReportStatus int8
+
+const (
+	ReportStatusSemantic ReportStatus = iota
+	ReportStatusLastKnown
+	ReportStatusCrowdsourced
+	ReportStatusAggregated
+)
+
+func (s *ReportStatus) String() string {
+	switch *s {
+	case ReportStatusSemantic:
+		return "semantic"
+	case ReportStatusLastKnown:
+		return "last_known"
+	case ReportStatusCrowdsourced:
+		return "crowdsourced"
+	case ReportStatusAggregated:
+		return "aggregated"
+	default:
+		return "unknown"
+	}
+}
+
+func (s ReportStatus) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *ReportStatus) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "semantic":
+		*s = ReportStatusSemantic
+	case "last_known":
+		*s = ReportStatusLastKnown
+	case "crowdsourced":
+		*s = ReportStatusCrowdsourced
+	case "aggregated":
+		*s = ReportStatusAggregated
+	default:
+		return fmt.Errorf("unknown report status: %s", text)
+	}
+
+	return nil
+}
+
+// HasLocation reports whether the report has coordinates, which semantic
+// reports only have when their name is a known semantic location
+func (l *LocationReport) HasLocation() bool {
+	if l.ReportType == ReportTypeLocation {
+		return true
+	}
+
+	return l.Latitude != 0 || l.Longitude != 0
+}
+
 func (l *LocationReport) String() string {
 	reportType := l.ReportType
 
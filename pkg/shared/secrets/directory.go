@@ -0,0 +1,112 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// DirectoryProvider reads one key per file from a directory, as mounted by
+// Docker secrets (/run/secrets) or a Kubernetes secret volume. Files starting
+// with a dot, such as the ..data links Kubernetes creates, are skipped.
+type DirectoryProvider struct {
+	mu  sync.Mutex
+	dir string
+}
+
+func NewDirectoryProvider(dir string) *DirectoryProvider {
+	newProvider := &DirectoryProvider{
+		dir: dir,
+	}
+
+	return newProvider
+}
+
+func (d *DirectoryProvider) GetSecret(ctx context.Context) (map[string]any, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read secret directory: %w", err)
+	}
+
+	secret := map[string]any{}
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		path := filepath.Join(d.dir, entry.Name())
+
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() {
+			continue
+		}
+
+		value, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read secret %s: %w", entry.Name(), err)
+		}
+
+		secret[entry.Name()] = decodeValue(value)
+	}
+
+	return secret, nil
+}
+
+// PutSecret writes each changed key back to its own file. Kubernetes secret
+// volumes are mounted read only, so this only works for writable directories.
+func (d *DirectoryProvider) PutSecret(ctx context.Context, secret map[string]any) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for key, value := range secret {
+		if strings.ContainsAny(key, `/\`) || strings.HasPrefix(key, ".") {
+			return fmt.Errorf("invalid secret key: %s", key)
+		}
+
+		valueBytes, err := encodeValue(value)
+		if err != nil {
+			return err
+		}
+
+		path := filepath.Join(d.dir, key)
+
+		current, err := os.ReadFile(path)
+		if err == nil && reflect.DeepEqual(decodeValue(current), decodeValue(valueBytes)) {
+			continue
+		}
+
+		tmpPath := path + ".tmp"
+
+		err = os.WriteFile(tmpPath, valueBytes, 0600)
+		if err != nil {
+			os.Remove(tmpPath)
+			return writeError(key, err)
+		}
+
+		err = os.Rename(tmpPath, path)
+		if err != nil {
+			os.Remove(tmpPath)
+			return writeError(key, err)
+		}
+	}
+
+	return nil
+}
+
+// writeError wraps errors from a read only mount with ErrReadOnly
+func writeError(key string, err error) error {
+	if errors.Is(err, syscall.EROFS) || errors.Is(err, syscall.EACCES) {
+		return fmt.Errorf("%w: %w", ErrReadOnly, err)
+	}
+
+	return fmt.Errorf("unable to write secret %s: %w", key, err)
+}
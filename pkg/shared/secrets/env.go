@@ -0,0 +1,43 @@
+package secrets
+
+import (
+	"context"
+	"os"
+	"strings"
+)
+
+// EnvProvider reads every environment variable starting with a prefix, so
+// FINDMY_MQTT_PASSWORD becomes the MQTT_PASSWORD key. JSON values such as
+// FINDMY_SESSION are decoded.
+type EnvProvider struct {
+	prefix string
+}
+
+func NewEnvProvider(prefix string) *EnvProvider {
+	newProvider := &EnvProvider{
+		prefix: prefix,
+	}
+
+	return newProvider
+}
+
+func (e *EnvProvider) GetSecret(ctx context.Context) (map[string]any, error) {
+	secret := map[string]any{}
+
+	for _, env := range os.Environ() {
+		key, value, _ := strings.Cut(env, "=")
+
+		secretKey, hasPrefix := strings.CutPrefix(key, e.prefix)
+		if !hasPrefix || secretKey == "" {
+			continue
+		}
+
+		secret[secretKey] = decodeValue([]byte(value))
+	}
+
+	return secret, nil
+}
+
+func (e *EnvProvider) PutSecret(ctx context.Context, secret map[string]any) error {
+	return ErrReadOnly
+}
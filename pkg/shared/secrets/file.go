@@ -0,0 +1,143 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileProvider keeps the secret in a local JSON or YAML file, picked by the
+// file extension
+type FileProvider struct {
+	mu     sync.Mutex
+	path   string
+	isYaml bool
+}
+
+func NewFileProvider(path string) *FileProvider {
+	extension := strings.ToLower(filepath.Ext(path))
+
+	newProvider := &FileProvider{
+		path:   path,
+		isYaml: extension == ".yaml" || extension == ".yml",
+	}
+
+	return newProvider
+}
+
+func (f *FileProvider) GetSecret(ctx context.Context) (map[string]any, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	secretBytes, err := os.ReadFile(f.path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read secret file: %w", err)
+	}
+
+	secret := map[string]any{}
+	if f.isYaml {
+		err = yaml.Unmarshal(secretBytes, &secret)
+	} else {
+		decoder := json.NewDecoder(bytes.NewReader(secretBytes))
+		decoder.UseNumber()
+
+		err = decoder.Decode(&secret)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse secret file: %w", err)
+	}
+
+	return secret, nil
+}
+
+// PutSecret writes the secret to a temporary file and renames it over the
+// old one, so a crash never leaves a partially written secret behind
+func (f *FileProvider) PutSecret(ctx context.Context, secret map[string]any) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var secretBytes []byte
+	var err error
+	if f.isYaml {
+		secretBytes, err = yaml.Marshal(yamlNumbers(secret))
+	} else {
+		secretBytes, err = json.MarshalIndent(secret, "", "\t")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(f.path)
+
+	tmpFile, err := os.CreateTemp(dir, filepath.Base(f.path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("unable to write secret file: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	_, err = tmpFile.Write(secretBytes)
+	if err == nil {
+		err = tmpFile.Chmod(0600)
+	}
+
+	closeErr := tmpFile.Close()
+	if err != nil {
+		return fmt.Errorf("unable to write secret file: %w", err)
+	}
+
+	if closeErr != nil {
+		return fmt.Errorf("unable to write secret file: %w", closeErr)
+	}
+
+	return os.Rename(tmpFile.Name(), f.path)
+}
+
+// yamlNumbers converts json.Number values, as returned by the Vault and JSON
+// providers, to numbers so they aren't written to YAML as strings
+func yamlNumbers(value any) any {
+	switch typedValue := value.(type) {
+	case json.Number:
+		intValue, err := strconv.ParseInt(typedValue.String(), 10, 64)
+		if err == nil {
+			return intValue
+		}
+
+		uintValue, err := strconv.ParseUint(typedValue.String(), 10, 64)
+		if err == nil {
+			return uintValue
+		}
+
+		floatValue, err := typedValue.Float64()
+		if err == nil {
+			return floatValue
+		}
+
+		return typedValue.String()
+	case map[string]any:
+		converted := make(map[string]any, len(typedValue))
+		for key, item := range typedValue {
+			converted[key] = yamlNumbers(item)
+		}
+
+		return converted
+	case []any:
+		converted := make([]any, len(typedValue))
+		for i, item := range typedValue {
+			converted[i] = yamlNumbers(item)
+		}
+
+		return converted
+	}
+
+	return value
+}
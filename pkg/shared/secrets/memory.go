@@ -0,0 +1,36 @@
+package secrets
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryProvider keeps the secret in memory, for tests
+type MemoryProvider struct {
+	mu     sync.RWMutex
+	secret map[string]any
+}
+
+func NewMemoryProvider(secret map[string]any) *MemoryProvider {
+	newProvider := &MemoryProvider{
+		secret: cloneSecret(secret),
+	}
+
+	return newProvider
+}
+
+func (m *MemoryProvider) GetSecret(ctx context.Context) (map[string]any, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return cloneSecret(m.secret), nil
+}
+
+func (m *MemoryProvider) PutSecret(ctx context.Context, secret map[string]any) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.secret = cloneSecret(secret)
+
+	return nil
+}
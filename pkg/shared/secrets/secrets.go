@@ -0,0 +1,61 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+)
+
+var (
+	ErrReadOnly = errors.New("secret provider is read only")
+)
+
+// SecretProvider loads the go-findmy secret, a map of keys such as SESSION,
+// SEMANTIC_LOCATIONS and MQTT_PASSWORD to their values
+type SecretProvider interface {
+	GetSecret(ctx context.Context) (map[string]any, error)
+
+	// PutSecret replaces the stored secret, providers that can't be written to
+	// return ErrReadOnly
+	PutSecret(ctx context.Context, secret map[string]any) error
+}
+
+// decodeValue returns JSON objects and arrays decoded, and anything else as
+// the trimmed string, so structured keys such as SESSION can be stored in
+// environment variables or secret files
+func decodeValue(value []byte) any {
+	trimmed := bytes.TrimSpace(value)
+
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		decoder := json.NewDecoder(bytes.NewReader(trimmed))
+		decoder.UseNumber()
+
+		var decoded any
+		err := decoder.Decode(&decoded)
+		if err == nil {
+			return decoded
+		}
+	}
+
+	return string(trimmed)
+}
+
+// encodeValue is the inverse of decodeValue
+func encodeValue(value any) ([]byte, error) {
+	stringValue, isString := value.(string)
+	if isString {
+		return []byte(stringValue), nil
+	}
+
+	return json.Marshal(value)
+}
+
+func cloneSecret(secret map[string]any) map[string]any {
+	if secret == nil {
+		return map[string]any{}
+	}
+
+	return maps.Clone(secret)
+}
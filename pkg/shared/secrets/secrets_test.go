@@ -0,0 +1,190 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var testSecret = map[string]any{
+	"MQTT_PASSWORD": "hunter2",
+	"SESSION": map[string]any{
+		"username":  "someone",
+		"androidId": json.Number("4439583726485123456"),
+	},
+}
+
+// assertSecret checks the keys the service reads survive a round trip,
+// including android ids too large for a float64
+func assertSecret(t *testing.T, secret map[string]any) {
+	t.Helper()
+
+	if secret["MQTT_PASSWORD"] != "hunter2" {
+		t.Fatalf("unexpected MQTT_PASSWORD %v", secret["MQTT_PASSWORD"])
+	}
+
+	session, ok := secret["SESSION"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected SESSION to be decoded, got %T", secret["SESSION"])
+	}
+
+	var androidId struct {
+		AndroidId uint64 `json:"androidId"`
+	}
+
+	sessionBytes, _ := json.Marshal(session)
+	err := json.Unmarshal(sessionBytes, &androidId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if androidId.AndroidId != 4439583726485123456 {
+		t.Fatalf("android id lost precision: %d", androidId.AndroidId)
+	}
+}
+
+func TestMemoryProvider(t *testing.T) {
+	ctx := context.Background()
+
+	provider := NewMemoryProvider(nil)
+
+	err := provider.PutSecret(ctx, testSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secret, err := provider.GetSecret(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertSecret(t, secret)
+
+	secret["MQTT_PASSWORD"] = "changed"
+
+	stored, _ := provider.GetSecret(ctx)
+	if stored["MQTT_PASSWORD"] != "hunter2" {
+		t.Fatal("expected GetSecret to return a copy")
+	}
+}
+
+func TestFileProvider(t *testing.T) {
+	for _, name := range []string{"secret.json", "secret.yaml"} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			provider := NewFileProvider(filepath.Join(t.TempDir(), name))
+
+			err := provider.PutSecret(ctx, testSecret)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			secret, err := provider.GetSecret(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assertSecret(t, secret)
+		})
+	}
+}
+
+func TestEnvProvider(t *testing.T) {
+	t.Setenv("FINDMY_TEST_MQTT_PASSWORD", "hunter2")
+	t.Setenv("FINDMY_TEST_SESSION", `{"username": "someone", "androidId": 4439583726485123456}`)
+
+	secret, err := NewEnvProvider("FINDMY_TEST_").GetSecret(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertSecret(t, secret)
+
+	err = NewEnvProvider("FINDMY_TEST_").PutSecret(context.Background(), secret)
+	if !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("expected ErrReadOnly, got %v", err)
+	}
+}
+
+func TestDirectoryProvider(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(dir, "MQTT_PASSWORD"), []byte("hunter2\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.WriteFile(filepath.Join(dir, "SESSION"), []byte(`{"username": "someone", "androidId": 4439583726485123456}`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.WriteFile(filepath.Join(dir, "..data"), []byte("ignored"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	provider := NewDirectoryProvider(dir)
+
+	secret, err := provider.GetSecret(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertSecret(t, secret)
+
+	if len(secret) != 2 {
+		t.Fatalf("expected hidden files to be skipped, got %v", secret)
+	}
+
+	err = provider.PutSecret(ctx, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	password, err := os.ReadFile(filepath.Join(dir, "MQTT_PASSWORD"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(password) != "hunter2\n" {
+		t.Fatalf("expected an unchanged key not to be rewritten, got %q", password)
+	}
+
+	secret, err = provider.GetSecret(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertSecret(t, secret)
+}
+
+func TestDirectoryProviderWriteFailure(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	// a directory in place of the key makes the rename fail
+	err := os.Mkdir(filepath.Join(dir, "SESSION"), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = NewDirectoryProvider(dir).PutSecret(ctx, map[string]any{"SESSION": "changed"})
+	if err == nil {
+		t.Fatal("expected the write to fail")
+	}
+
+	if errors.Is(err, ErrReadOnly) {
+		t.Fatalf("expected a writable directory not to be reported as read only, got %v", err)
+	}
+
+	_, err = os.Stat(filepath.Join(dir, "SESSION.tmp"))
+	if !os.IsNotExist(err) {
+		t.Fatalf("expected the temporary file to be removed, got %v", err)
+	}
+}
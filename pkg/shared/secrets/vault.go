@@ -0,0 +1,38 @@
+package secrets
+
+import (
+	"context"
+
+	"github.com/dylanmazurek/go-findmy/pkg/shared/vault"
+)
+
+// VaultProvider reads and writes a HashiCorp Vault KVv2 secret
+type VaultProvider struct {
+	client     *vault.Client
+	mountPath  string
+	secretPath string
+}
+
+// NewVaultProvider logs in to Vault with an AppRole
+func NewVaultProvider(ctx context.Context, addr string, appRoleId string, secretId string, mountPath string, secretPath string) (*VaultProvider, error) {
+	vaultClient, err := vault.NewClient(ctx, addr, appRoleId, secretId)
+	if err != nil {
+		return nil, err
+	}
+
+	newProvider := &VaultProvider{
+		client:     vaultClient,
+		mountPath:  mountPath,
+		secretPath: secretPath,
+	}
+
+	return newProvider, nil
+}
+
+func (v *VaultProvider) GetSecret(ctx context.Context) (map[string]any, error) {
+	return v.client.GetSecret(ctx, v.mountPath, v.secretPath)
+}
+
+func (v *VaultProvider) PutSecret(ctx context.Context, secret map[string]any) error {
+	return v.client.InsertSecret(ctx, v.mountPath, v.secretPath, secret)
+}